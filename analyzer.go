@@ -0,0 +1,126 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+)
+
+// analyzerFloor is the smallest power reported by an Analyzer, -200 dB.
+const analyzerFloor = 1e-20
+
+// Analyzer is a real-time spectrum analyzer. It consumes streaming blocks of
+// any length and, every Hop samples, windows the most recent Size samples,
+// transforms them and folds the power spectrum into an averager. The result
+// is reported in dB, where a full scale sine of amplitude 1 reads -3 dB.
+type Analyzer struct {
+	size, hop int
+	fS        float64
+	window    []float64
+	wsum      float64
+	buf       *RingBuffer
+	avg       *SpectrumAverager
+	pending   int
+	spectrum  DataSet
+
+	plan  *FFTPlan
+	frame []float64
+	bins  []complex128
+	power []float64
+
+	// OnUpdate, if set, is called with each new averaged dB spectrum.
+	OnUpdate func(DataSet)
+}
+
+// NewAnalyzer creates an analyzer computing Size point Hann windowed spectra
+// every Hop samples. If avg is nil, spectra are not averaged.
+func NewAnalyzer(size, hop int, fS float64, avg *SpectrumAverager) *Analyzer {
+	if size <= 0 || hop <= 0 {
+		log.Fatalf("NewAnalyzer requires a positive size and hop, got %d and %d", size, hop)
+	}
+	if avg == nil {
+		avg = NewSpectrumAverager(ExponentialAveraging, 1)
+	}
+	window := Hann(size)
+	return &Analyzer{
+		size:   size,
+		hop:    hop,
+		fS:     fS,
+		window: window,
+		wsum:   DataSet(window).Sum(),
+		buf:    NewRingBuffer(size),
+		avg:    avg,
+		plan:   NewFFTPlan(size),
+		frame:  make([]float64, size),
+		bins:   make([]complex128, size/2+1),
+		power:  make([]float64, size/2+1),
+	}
+}
+
+// Process consumes a block of samples and returns the number of spectrum
+// updates it produced.
+func (a *Analyzer) Process(block []float64) int {
+	updates := 0
+	for len(block) > 0 {
+		n := minInt(len(block), a.hop-a.pending)
+		a.buf.Write(block[:n])
+		block = block[n:]
+		a.pending += n
+		if a.pending == a.hop && a.buf.Len() == a.size {
+			a.update()
+			updates++
+		}
+		if a.pending == a.hop {
+			a.pending = 0
+		}
+	}
+	return updates
+}
+
+// Spectrum returns the latest averaged spectrum in dB, or nil before the first
+// update.
+func (a *Analyzer) Spectrum() DataSet {
+	if a.spectrum == nil {
+		return nil
+	}
+	s := make([]float64, len(a.spectrum))
+	copy(s, a.spectrum)
+	return s
+}
+
+// Frequencies returns the frequency of each spectrum bin.
+func (a *Analyzer) Frequencies() DataSet {
+	freqs := FFTFreqs(a.size, a.fS)[:a.size/2+1]
+	freqs[a.size/2] = a.fS * float64(a.size/2) / float64(a.size)
+	return freqs
+}
+
+// Reset clears the buffered samples, the average and the latest spectrum.
+func (a *Analyzer) Reset() {
+	a.buf.Reset()
+	a.avg.Reset()
+	a.pending = 0
+	a.spectrum = nil
+}
+
+// update computes the power spectrum of the buffered samples and averages it.
+func (a *Analyzer) update() {
+	for i := range a.frame {
+		a.frame[i] = a.buf.At(i) * a.window[i]
+	}
+	a.plan.ForwardReal(a.bins, a.frame)
+
+	for k, v := range a.bins {
+		amp := 2 * cmplx.Abs(v) / a.wsum
+		a.power[k] = amp * amp / 2
+	}
+	avg := a.avg.Add(a.power)
+
+	a.spectrum = make([]float64, len(avg))
+	for k, v := range avg {
+		a.spectrum[k] = 10 * math.Log10(math.Max(v, analyzerFloor))
+	}
+	if a.OnUpdate != nil {
+		a.OnUpdate(a.Spectrum())
+	}
+}
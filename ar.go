@@ -0,0 +1,179 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+)
+
+// ARMethod selects the algorithm used to estimate an autoregressive model.
+type ARMethod int
+
+const (
+	// YuleWalkerMethod solves the Yule-Walker equations from the biased
+	// autocorrelation.
+	YuleWalkerMethod ARMethod = iota
+
+	// BurgMethod minimizes the forward and backward prediction errors, which
+	// gives better estimates on short records.
+	BurgMethod
+)
+
+// ARModel is an autoregressive model x[n] + a1 x[n-1] + ... + ap x[n-p] = e[n]
+// driven by white noise e with the given variance.
+type ARModel struct {
+	// A contains the model polynomial coefficients with A[0] = 1.
+	A DataSet
+
+	// Reflection contains the reflection (partial correlation) coefficients.
+	Reflection DataSet
+
+	// Variance is the variance of the driving white noise.
+	Variance float64
+}
+
+// Order returns the model order.
+func (m *ARModel) Order() int {
+	return len(m.A) - 1
+}
+
+// YuleWalker estimates an AR model of the given order with the Yule-Walker
+// method.
+func (d DataSet) YuleWalker(order int) *ARModel {
+	checkAROrder("YuleWalker", order, len(d))
+	mean := d.Mean()
+	x := make(DataSet, len(d))
+	for i := 0; i < len(d); i++ {
+		x[i] = d[i] - mean
+	}
+	r := x.Autocorrelation(order)
+	for i := range r {
+		r[i] /= float64(len(d))
+	}
+	a, k, e := levinson(r, order)
+	return &ARModel{A: a, Reflection: k, Variance: e}
+}
+
+// Burg estimates an AR model of the given order with Burg's method.
+func (d DataSet) Burg(order int) *ARModel {
+	checkAROrder("Burg", order, len(d))
+	mean := d.Mean()
+	n := len(d)
+	f := make([]float64, n)
+	b := make([]float64, n)
+	var e float64
+	for i := 0; i < n; i++ {
+		f[i] = d[i] - mean
+		b[i] = f[i]
+		e += f[i] * f[i]
+	}
+	e /= float64(n)
+
+	a := []float64{1}
+	k := make([]float64, order)
+	for m := 1; m <= order; m++ {
+		var num, den float64
+		for i := m; i < n; i++ {
+			num += f[i] * b[i-1]
+			den += f[i]*f[i] + b[i-1]*b[i-1]
+		}
+		km := -2 * num / den
+		k[m-1] = km
+
+		// update the prediction errors from the end so b[i-1] is still old
+		for i := n - 1; i >= m; i-- {
+			fi := f[i]
+			f[i] = fi + km*b[i-1]
+			b[i] = b[i-1] + km*fi
+		}
+		a = stepUp(a, km)
+		e *= 1 - km*km
+	}
+	return &ARModel{A: a, Reflection: k, Variance: e}
+}
+
+// SelectAROrder fits AR models of order 1 through maxOrder and returns the one
+// minimizing the Akaike information criterion, along with the criterion for
+// each order.
+func (d DataSet) SelectAROrder(maxOrder int, method ARMethod) (*ARModel, DataSet) {
+	n := float64(len(d))
+	aic := make([]float64, maxOrder)
+	var best *ARModel
+	for p := 1; p <= maxOrder; p++ {
+		var m *ARModel
+		if method == BurgMethod {
+			m = d.Burg(p)
+		} else {
+			m = d.YuleWalker(p)
+		}
+		aic[p-1] = n*math.Log(m.Variance) + 2*float64(p)
+		if best == nil || aic[p-1] < aic[best.Order()-1] {
+			best = m
+		}
+	}
+	return best, aic
+}
+
+// PSD returns the one-sided power spectral density of the model evaluated at
+// n frequencies evenly spaced from 0 to fS/2.
+func (m *ARModel) PSD(n int, fS float64) (DataSet, DataSet) {
+	freqs := make([]float64, n)
+	psd := make([]float64, n)
+	for i := 0; i < n; i++ {
+		f := 0.0
+		if n > 1 {
+			f = float64(i) * fS / 2 / float64(n-1)
+		}
+		var A complex128
+		for j, a := range m.A {
+			A += complex(a, 0) * cmplx.Rect(1, -2*math.Pi*f*float64(j)/fS)
+		}
+		mag := cmplx.Abs(A)
+		freqs[i] = f
+		psd[i] = 2 * m.Variance / fS / (mag * mag)
+		if i == 0 || i == n-1 {
+			psd[i] /= 2
+		}
+	}
+	return freqs, psd
+}
+
+// levinson solves the Toeplitz normal equations for the autocorrelation r
+// with the Levinson-Durbin recursion. It returns the prediction polynomial,
+// the reflection coefficients and the final prediction error.
+func levinson(r DataSet, order int) (DataSet, DataSet, float64) {
+	a := []float64{1}
+	k := make([]float64, order)
+	e := r[0]
+	for m := 1; m <= order; m++ {
+		acc := r[m]
+		for i := 1; i < m; i++ {
+			acc += a[i] * r[m-i]
+		}
+		km := -acc / e
+		k[m-1] = km
+		a = stepUp(a, km)
+		e *= 1 - km*km
+	}
+	return a, k, e
+}
+
+// stepUp extends a prediction polynomial by one order using the reflection
+// coefficient k.
+func stepUp(a []float64, k float64) []float64 {
+	m := len(a)
+	next := make([]float64, m+1)
+	copy(next, a)
+	for i := 1; i <= m; i++ {
+		next[i] += k * a[m-i]
+	}
+	return next
+}
+
+// checkAROrder exits if an AR model of the given order cannot be fitted to n
+// samples.
+func checkAROrder(name string, order, n int) {
+	if order < 0 || order >= n {
+		log.Fatalf("%s requires an order between 0 and %d, got %d", name, n-1, order)
+	}
+}
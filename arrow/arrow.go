@@ -0,0 +1,229 @@
+// Package arrow writes columns of float64 values in the Apache Arrow IPC
+// formats, so that feature matrices and other tables can be loaded directly
+// by pyarrow, pandas, polars and other Arrow-based tools. It implements only
+// what is needed to write non-nullable float64 columns, without depending on
+// the Arrow libraries.
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Arrow metadata constants from Schema.fbs and Message.fbs.
+const (
+	metadataV5        = 4
+	headerSchema      = 1
+	headerRecordBatch = 3
+	typeFloatingPoint = 3
+	precisionDouble   = 2
+)
+
+// magic starts and ends the IPC file format.
+const magic = "ARROW1"
+
+// block locates a record batch message in an IPC file.
+type block struct {
+	offset     int64
+	metaLength int32
+	bodyLength int64
+}
+
+// WriteFile writes the columns as one record batch in the Arrow IPC file
+// format, also known as Feather V2, usually saved with an .arrow or .feather
+// extension. Every column must have the same length.
+func WriteFile(w io.Writer, names []string, columns [][]float64) error {
+	if err := checkColumns(names, columns); err != nil {
+		return err
+	}
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write([]byte(magic + "\x00\x00")); err != nil {
+		return err
+	}
+	if _, err := writeMessage(cw, headerSchema, schema(names), nil); err != nil {
+		return err
+	}
+	offset := cw.n
+	meta, err := writeMessage(cw, headerRecordBatch, recordBatch(columns), body(columns))
+	if err != nil {
+		return err
+	}
+	batch := block{offset: offset, metaLength: int32(meta), bodyLength: int64(bodyLength(columns))}
+	if err := writeEOS(cw); err != nil {
+		return err
+	}
+
+	footer := finishFlatbuffer(&fbTable{fields: []fbValue{
+		fbShort(metadataV5),
+		fbRef(schema(names)),
+		fbRef(fbStructVector{}),
+		fbRef(blocks(batch)),
+	}})
+	if _, err := cw.Write(footer); err != nil {
+		return err
+	}
+	if err := binary.Write(cw, binary.LittleEndian, int32(len(footer))); err != nil {
+		return err
+	}
+	_, err = cw.Write([]byte(magic))
+	return err
+}
+
+// WriteStream writes the columns as one record batch in the Arrow IPC
+// streaming format. Every column must have the same length.
+func WriteStream(w io.Writer, names []string, columns [][]float64) error {
+	if err := checkColumns(names, columns); err != nil {
+		return err
+	}
+	if _, err := writeMessage(w, headerSchema, schema(names), nil); err != nil {
+		return err
+	}
+	if _, err := writeMessage(w, headerRecordBatch, recordBatch(columns), body(columns)); err != nil {
+		return err
+	}
+	return writeEOS(w)
+}
+
+// checkColumns returns an error if the columns do not form a table.
+func checkColumns(names []string, columns [][]float64) error {
+	if len(names) != len(columns) {
+		return fmt.Errorf("arrow: %d names for %d columns", len(names), len(columns))
+	}
+	for i, c := range columns {
+		if len(c) != len(columns[0]) {
+			return fmt.Errorf("arrow: column %q has %d rows, want %d", names[i], len(c), len(columns[0]))
+		}
+	}
+	return nil
+}
+
+// schema returns the Schema table for float64 columns with the given names.
+func schema(names []string) *fbTable {
+	fields := make(fbTableVector, len(names))
+	for i, name := range names {
+		fields[i] = &fbTable{fields: []fbValue{
+			fbRef(fbString(name)),
+			fbByte(0),
+			fbByte(typeFloatingPoint),
+			fbRef(&fbTable{fields: []fbValue{fbShort(precisionDouble)}}),
+			{},
+			fbRef(fbTableVector{}),
+		}}
+	}
+	return &fbTable{fields: []fbValue{{}, fbRef(fields)}}
+}
+
+// recordBatch returns the RecordBatch table describing the body written by
+// body: a field node per column and an empty validity buffer and a data
+// buffer for each.
+func recordBatch(columns [][]float64) *fbTable {
+	var rows int
+	if len(columns) > 0 {
+		rows = len(columns[0])
+	}
+	nodes := fbStructVector{count: len(columns)}
+	buffers := fbStructVector{count: 2 * len(columns)}
+	var offset int64
+	for range columns {
+		nodes.data = appendLongs(nodes.data, int64(rows), 0)
+		buffers.data = appendLongs(buffers.data, offset, 0, offset, int64(8*rows))
+		offset += int64(padded(8 * rows))
+	}
+	return &fbTable{fields: []fbValue{
+		fbLong(int64(rows)),
+		fbRef(nodes),
+		fbRef(buffers),
+	}}
+}
+
+// body returns the record batch body, each column's values padded to eight
+// bytes.
+func body(columns [][]float64) []byte {
+	b := make([]byte, 0, bodyLength(columns))
+	for _, c := range columns {
+		for _, v := range c {
+			b = appendLongs(b, int64(math.Float64bits(v)))
+		}
+		for len(b)%8 != 0 {
+			b = append(b, 0)
+		}
+	}
+	return b
+}
+
+// bodyLength returns the length of the record batch body.
+func bodyLength(columns [][]float64) int {
+	n := 0
+	for _, c := range columns {
+		n += padded(8 * len(c))
+	}
+	return n
+}
+
+// blocks returns the vector of Block structs for the footer.
+func blocks(batches ...block) fbStructVector {
+	v := fbStructVector{count: len(batches)}
+	for _, b := range batches {
+		v.data = appendLongs(v.data, b.offset, int64(uint32(b.metaLength)), b.bodyLength)
+	}
+	return v
+}
+
+// writeMessage writes an encapsulated IPC message: the continuation marker,
+// the metadata length, the Message flatbuffer padded to eight bytes and the
+// body. It returns the length of everything before the body.
+func writeMessage(w io.Writer, headerType uint8, header *fbTable, body []byte) (int, error) {
+	meta := finishFlatbuffer(&fbTable{fields: []fbValue{
+		fbShort(metadataV5),
+		fbByte(headerType),
+		fbRef(header),
+		fbLong(int64(len(body))),
+	}})
+	for (len(meta)+8)%8 != 0 {
+		meta = append(meta, 0)
+	}
+	prefix := make([]byte, 8)
+	binary.LittleEndian.PutUint32(prefix, 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(meta)))
+	for _, b := range [][]byte{prefix, meta, body} {
+		if _, err := w.Write(b); err != nil {
+			return 0, err
+		}
+	}
+	return len(prefix) + len(meta), nil
+}
+
+// writeEOS writes the end of stream marker.
+func writeEOS(w io.Writer) error {
+	_, err := w.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0})
+	return err
+}
+
+// appendLongs appends little-endian int64 values.
+func appendLongs(b []byte, values ...int64) []byte {
+	for _, v := range values {
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		b = append(b, buf[:]...)
+	}
+	return b
+}
+
+// padded rounds n up to a multiple of eight.
+func padded(n int) int {
+	return (n + 7) &^ 7
+}
+
+// countingWriter counts the bytes written, for the footer block offsets.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,179 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// fbTable is a flatbuffer table, with one value per field id. Absent fields
+// are left as the zero value and take their schema default.
+type fbTable struct {
+	fields []fbValue
+}
+
+// fbValue is a table field, either an inline little-endian scalar or a
+// reference to a table, vector or string.
+type fbValue struct {
+	scalar []byte
+	ref    interface{}
+}
+
+// fbTableVector is a vector of tables.
+type fbTableVector []*fbTable
+
+// fbStructVector is a vector of structs with 8-byte alignment, given as the
+// raw bytes of its elements.
+type fbStructVector struct {
+	count int
+	data  []byte
+}
+
+// fbString is a flatbuffer string.
+type fbString string
+
+// fbShort, fbByte and fbLong encode inline scalars.
+func fbShort(v int16) fbValue {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, uint16(v))
+	return fbValue{scalar: b}
+}
+
+func fbByte(v uint8) fbValue {
+	return fbValue{scalar: []byte{v}}
+}
+
+func fbLong(v int64) fbValue {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(v))
+	return fbValue{scalar: b}
+}
+
+// fbRef refers to a table, vector or string.
+func fbRef(o interface{}) fbValue {
+	return fbValue{ref: o}
+}
+
+// finishFlatbuffer lays out a flatbuffer with the given root table. Objects
+// are written after the fields that refer to them, since flatbuffer offsets
+// are unsigned and point forwards.
+func finishFlatbuffer(root *fbTable) []byte {
+	w := &fbWriter{buf: make([]byte, 4)}
+	pos := w.write(root)
+	binary.LittleEndian.PutUint32(w.buf, uint32(pos))
+	return w.buf
+}
+
+// fbWriter accumulates a flatbuffer front to back.
+type fbWriter struct {
+	buf []byte
+}
+
+// pad appends zeros until the length plus extra is a multiple of align.
+func (w *fbWriter) pad(align, extra int) {
+	for (len(w.buf)+extra)%align != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// u32 appends a little-endian uint32 and returns its position.
+func (w *fbWriter) u32(v uint32) int {
+	pos := len(w.buf)
+	w.buf = append(w.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(w.buf[pos:], v)
+	return pos
+}
+
+// patch points the offset at pos to the object written next.
+func (w *fbWriter) patch(pos int, o interface{}) {
+	target := w.write(o)
+	binary.LittleEndian.PutUint32(w.buf[pos:], uint32(target-pos))
+}
+
+// write appends an object and everything it refers to, returning the
+// position its references must point at.
+func (w *fbWriter) write(o interface{}) int {
+	switch o := o.(type) {
+	case *fbTable:
+		return w.table(o)
+
+	case fbTableVector:
+		w.pad(4, 0)
+		pos := w.u32(uint32(len(o)))
+		slots := make([]int, len(o))
+		for i := range o {
+			slots[i] = w.u32(0)
+		}
+		for i, t := range o {
+			w.patch(slots[i], t)
+		}
+		return pos
+
+	case fbStructVector:
+		w.pad(8, 4)
+		pos := w.u32(uint32(o.count))
+		w.buf = append(w.buf, o.data...)
+		return pos
+
+	case fbString:
+		w.pad(4, 0)
+		pos := w.u32(uint32(len(o)))
+		w.buf = append(w.buf, o...)
+		w.buf = append(w.buf, 0)
+		return pos
+	}
+	panic("arrow: unknown flatbuffer object")
+}
+
+// table writes the vtable and inline fields of a table, then the objects
+// its fields refer to.
+func (w *fbWriter) table(t *fbTable) int {
+	// place the fields largest first after the vtable offset, each aligned
+	// to its size
+	type slot struct {
+		id, size, offset int
+	}
+	var slots []slot
+	for id, f := range t.fields {
+		switch {
+		case f.ref != nil:
+			slots = append(slots, slot{id: id, size: 4})
+		case f.scalar != nil:
+			slots = append(slots, slot{id: id, size: len(f.scalar)})
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].size > slots[j].size })
+	size := 4
+	for i := range slots {
+		for size%slots[i].size != 0 {
+			size++
+		}
+		slots[i].offset = size
+		size += slots[i].size
+	}
+
+	w.pad(2, 0)
+	vtable := len(w.buf)
+	entries := make([]byte, 4+2*len(t.fields))
+	binary.LittleEndian.PutUint16(entries, uint16(len(entries)))
+	binary.LittleEndian.PutUint16(entries[2:], uint16(size))
+	for _, s := range slots {
+		binary.LittleEndian.PutUint16(entries[4+2*s.id:], uint16(s.offset))
+	}
+	w.buf = append(w.buf, entries...)
+
+	w.pad(8, 0)
+	start := len(w.buf)
+	w.buf = append(w.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(w.buf[start:], uint32(int32(start-vtable)))
+	for _, s := range slots {
+		if f := t.fields[s.id]; f.ref == nil {
+			copy(w.buf[start+s.offset:], f.scalar)
+		}
+	}
+	for _, s := range slots {
+		if f := t.fields[s.id]; f.ref != nil {
+			w.patch(start+s.offset, f.ref)
+		}
+	}
+	return start
+}
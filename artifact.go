@@ -0,0 +1,140 @@
+package dsp
+
+// ReferenceProcessor is a stateful stream processor which cleans a primary
+// channel using a simultaneously sampled reference channel, for example an
+// accelerometer recording the motion that corrupts a biosignal.
+type ReferenceProcessor interface {
+	// Process consumes a block of primary and reference samples and returns
+	// the cleaned primary block.
+	Process(X, ref []float64) []float64
+
+	// Reset clears the internal state.
+	Reset()
+}
+
+// NLMSCanceller removes the part of the primary signal that is linearly
+// predictable from the reference with a normalized LMS adaptive FIR filter.
+// It tracks slowly changing coupling between the artifact source and the
+// primary sensor.
+type NLMSCanceller struct {
+	// Mu is the adaptation step size, between 0 and 2.
+	Mu float64
+
+	weights []float64
+	history []float64
+
+	// running reference power, used to regularize the normalization
+	power float64
+}
+
+// nlmsRegularization is the fraction of the expected tap-window power added to
+// the NLMS normalization, so near-silent reference windows cannot blow up the
+// update.
+const nlmsRegularization = 0.1
+
+// NewNLMSCanceller creates an adaptive canceller with the given number of
+// filter taps and step size.
+func NewNLMSCanceller(taps int, mu float64) *NLMSCanceller {
+	return &NLMSCanceller{Mu: mu, weights: make([]float64, taps), history: make([]float64, taps)}
+}
+
+// Process cancels the reference-correlated artifact from a block of samples.
+func (c *NLMSCanceller) Process(X, ref []float64) []float64 {
+	out := make([]float64, len(X))
+	for n := range X {
+		copy(c.history[1:], c.history[:len(c.history)-1])
+		c.history[0] = ref[n]
+		c.power = 0.999*c.power + 0.001*ref[n]*ref[n]
+
+		var y, power float64
+		for i, w := range c.weights {
+			y += w * c.history[i]
+			power += c.history[i] * c.history[i]
+		}
+		e := X[n] - y
+		out[n] = e
+
+		delta := nlmsRegularization*c.power*float64(len(c.weights)) + 1e-12
+		step := c.Mu * e / (power + delta)
+		for i := range c.weights {
+			c.weights[i] += step * c.history[i]
+		}
+	}
+	return out
+}
+
+// Weights returns a copy of the current adaptive filter weights.
+func (c *NLMSCanceller) Weights() DataSet {
+	w := make([]float64, len(c.weights))
+	copy(w, c.weights)
+	return w
+}
+
+// Reset clears the filter weights and reference history.
+func (c *NLMSCanceller) Reset() {
+	for i := range c.weights {
+		c.weights[i] = 0
+		c.history[i] = 0
+	}
+	c.power = 0
+}
+
+// BandProjector removes artifacts by band-limiting the reference to the band
+// where the artifact lives, then projecting each primary block onto the lagged
+// band-limited reference and subtracting the least-squares fit. Unlike an
+// adaptive filter it has no convergence time, but the coupling is assumed
+// constant over each block.
+type BandProjector struct {
+	taps    int
+	hp, lp  *StreamFilter
+	history []float64
+}
+
+// NewBandProjector creates a band projector using the reference band between
+// fLow and fHigh Hz and the given number of reference lags.
+func NewBandProjector(fLow, fHigh, fS float64, taps int) *BandProjector {
+	return &BandProjector{
+		taps:    taps,
+		hp:      NewStreamFilter(NewHighPassFilter(fLow, fS)),
+		lp:      NewStreamFilter(NewLowPassFilter(fHigh, fS)),
+		history: make([]float64, taps-1),
+	}
+}
+
+// Process removes the projection onto the band-limited reference from a block.
+func (p *BandProjector) Process(X, ref []float64) []float64 {
+	band := p.lp.Process(p.hp.Process(ref))
+	lagged := append(append([]float64{}, p.history...), band...)
+	copy(p.history, lagged[len(lagged)-len(p.history):])
+
+	rows := make([][]float64, len(X))
+	for n := range X {
+		row := make([]float64, p.taps)
+		for i := range row {
+			row[i] = lagged[n+p.taps-1-i]
+		}
+		rows[n] = row
+	}
+
+	out := make([]float64, len(X))
+	copy(out, X)
+	coeffs := leastSquares(rows, X, 1e-9)
+	if coeffs == nil {
+		return out
+	}
+	for n, row := range rows {
+		for i, c := range coeffs {
+			out[n] -= c * row[i]
+		}
+	}
+	return out
+}
+
+// Reset clears the band filters and reference history.
+func (p *BandProjector) Reset() {
+	p.hp.Reset()
+	p.lp.Reset()
+	for i := range p.history {
+		p.history[i] = 0
+	}
+}
@@ -0,0 +1,116 @@
+package dsp
+
+import "math"
+
+// AveragingMode selects how successive spectra are combined.
+type AveragingMode int
+
+const (
+	// ExponentialAveraging weights each new spectrum by 1/N and the running
+	// average by 1 - 1/N, so older spectra fade out gradually.
+	ExponentialAveraging AveragingMode = iota
+
+	// LinearAveraging takes the mean of the last N spectra, or of every
+	// spectrum since the last reset if N is zero.
+	LinearAveraging
+
+	// PeakHold keeps the largest value seen in each bin.
+	PeakHold
+)
+
+// SpectrumAverager combines successive spectra bin by bin, like the averaging
+// modes of a spectrum analyzer.
+type SpectrumAverager struct {
+	mode AveragingMode
+	n    int
+
+	avg     []float64
+	sum     []float64
+	history [][]float64
+	pos     int
+	count   int
+}
+
+// NewSpectrumAverager creates an averager with the given mode and number of
+// spectra N.
+func NewSpectrumAverager(mode AveragingMode, n int) *SpectrumAverager {
+	return &SpectrumAverager{mode: mode, n: n}
+}
+
+// Add includes a spectrum in the average and returns the updated average.
+func (a *SpectrumAverager) Add(spectrum DataSet) DataSet {
+	if len(a.avg) != len(spectrum) {
+		a.Reset()
+		a.avg = make([]float64, len(spectrum))
+		a.sum = make([]float64, len(spectrum))
+	}
+	a.count++
+
+	switch a.mode {
+	case ExponentialAveraging:
+		alpha := 1.0
+		if a.count > 1 && a.n > 0 {
+			// start as a linear average until N spectra have been seen
+			alpha = 1 / math.Min(float64(a.count), float64(a.n))
+		}
+		for k, v := range spectrum {
+			a.avg[k] += alpha * (v - a.avg[k])
+		}
+
+	case LinearAveraging:
+		for k, v := range spectrum {
+			a.sum[k] += v
+		}
+		if a.n > 0 {
+			frame := make([]float64, len(spectrum))
+			copy(frame, spectrum)
+			if len(a.history) < a.n {
+				a.history = append(a.history, frame)
+			} else {
+				for k, v := range a.history[a.pos] {
+					a.sum[k] -= v
+				}
+				a.history[a.pos] = frame
+				a.pos = (a.pos + 1) % a.n
+			}
+		}
+		count := float64(a.count)
+		if a.n > 0 {
+			count = float64(len(a.history))
+		}
+		for k := range a.avg {
+			a.avg[k] = a.sum[k] / count
+		}
+
+	case PeakHold:
+		for k, v := range spectrum {
+			if a.count == 1 || v > a.avg[k] {
+				a.avg[k] = v
+			}
+		}
+	}
+	return a.Average()
+}
+
+// Average returns a copy of the current average.
+func (a *SpectrumAverager) Average() DataSet {
+	avg := make([]float64, len(a.avg))
+	copy(avg, a.avg)
+	return avg
+}
+
+// Count returns the number of spectra added since the last reset.
+func (a *SpectrumAverager) Count() int {
+	return a.count
+}
+
+// Reset discards the average.
+func (a *SpectrumAverager) Reset() {
+	for k := range a.avg {
+		a.avg[k] = 0
+		a.sum[k] = 0
+	}
+	a.history = nil
+	a.pos = 0
+	a.count = 0
+}
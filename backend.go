@@ -0,0 +1,80 @@
+package dsp
+
+// Backend performs the transforms of the package. Every FFT, including those
+// inside spectral estimators, STFTs, FFTPlan and FFTBatch, is computed by
+// Backend.FFT, and long convolutions and correlations by Backend.Convolve.
+// Implementations can delegate to accelerator libraries such as cuFFT or
+// clFFT, and should fall back to CPUBackend for transforms too small to
+// repay the cost of moving data to the device. FFTBatch calls the backend
+// from several goroutines at once, so implementations must be safe for
+// concurrent use.
+type Backend interface {
+	// FFT returns the discrete Fourier transform of x, or the inverse
+	// transform scaled by 1/N if inverse is true, without modifying x.
+	FFT(x []complex128, inverse bool) []complex128
+
+	// Convolve returns the full linear convolution of a and b, of length
+	// len(a)+len(b)-1.
+	Convolve(a, b []float64) []float64
+}
+
+// CPUBackend is the default backend, computing transforms in Go.
+type CPUBackend struct{}
+
+// backend is the backend used by the package.
+var backend Backend = CPUBackend{}
+
+// SetBackend replaces the backend used by the package; nil restores
+// CPUBackend. It must not be called while transforms are running.
+func SetBackend(b Backend) {
+	if b == nil {
+		b = CPUBackend{}
+	}
+	backend = b
+}
+
+// CurrentBackend returns the backend used by the package.
+func CurrentBackend() Backend {
+	return backend
+}
+
+// offloaded returns true if a backend other than CPUBackend is in use, so
+// that transforms must go through it rather than the in-place CPU code.
+func offloaded() bool {
+	_, cpu := backend.(CPUBackend)
+	return !cpu
+}
+
+// FFT transforms power of two lengths with a radix-2 FFT and other lengths
+// with Bluestein's algorithm.
+func (CPUBackend) FFT(x []complex128, inverse bool) []complex128 {
+	X := make([]complex128, len(x))
+	copy(X, x)
+	if isPow2(len(X)) {
+		radix2FFT(X, inverse)
+		return X
+	}
+	return cpuDFT(X, inverse)
+}
+
+// Convolve computes the convolution with zero-padded FFTs.
+func (CPUBackend) Convolve(a, b []float64) []float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return []float64{}
+	}
+	size := len(a) + len(b) - 1
+	n := NextPow2(size)
+	A := realToComplex(a, n)
+	B := realToComplex(b, n)
+	radix2FFT(A, false)
+	radix2FFT(B, false)
+	for k := range A {
+		A[k] *= B[k]
+	}
+	radix2FFT(A, true)
+	out := make([]float64, size)
+	for i := range out {
+		out[i] = real(A[i])
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+)
+
+// SpeedOfSound is the speed of sound in air at 20 degrees C in m/s.
+const SpeedOfSound = 343.0
+
+// mvdrLoading is the diagonal loading applied to covariance matrices before
+// MVDR weights are computed, relative to the average sensor power.
+const mvdrLoading = 1e-3
+
+// BeamformMethod selects a beamforming algorithm.
+type BeamformMethod int
+
+const (
+	// DelayAndSumMethod aligns and averages the channels (conventional
+	// beamforming).
+	DelayAndSumMethod BeamformMethod = iota
+
+	// MVDRMethod uses minimum variance distortionless response weights, which
+	// adaptively null interferers outside the look direction.
+	MVDRMethod
+)
+
+// Position is a sensor location in meters.
+type Position struct {
+	X, Y, Z float64
+}
+
+// Array describes the geometry of a sensor array.
+type Array struct {
+	Positions []Position
+
+	// Speed is the propagation speed of the wave in m/s.
+	Speed float64
+}
+
+// NewLinearArray creates a uniform linear array of n sensors along the x axis
+// with the given spacing in meters.
+func NewLinearArray(n int, spacing, speed float64) Array {
+	positions := make([]Position, n)
+	for i := range positions {
+		positions[i] = Position{X: float64(i) * spacing}
+	}
+	return Array{Positions: positions, Speed: speed}
+}
+
+// Delays returns the arrival time of a plane wave from the given azimuth and
+// elevation (in radians) at each sensor, relative to the array origin.
+func (a Array) Delays(az, el float64) DataSet {
+	ux := math.Cos(el) * math.Cos(az)
+	uy := math.Cos(el) * math.Sin(az)
+	uz := math.Sin(el)
+	delays := make([]float64, len(a.Positions))
+	for i, p := range a.Positions {
+		delays[i] = -(p.X*ux + p.Y*uy + p.Z*uz) / a.Speed
+	}
+	return delays
+}
+
+// SteeringVector returns the narrowband array response at frequency f to a
+// plane wave from the given azimuth and elevation.
+func (a Array) SteeringVector(az, el, f float64) []complex128 {
+	delays := a.Delays(az, el)
+	v := make([]complex128, len(delays))
+	for i, tau := range delays {
+		v[i] = cmplx.Rect(1, -2*math.Pi*f*tau)
+	}
+	return v
+}
+
+// DelayAndSum steers the array towards the given azimuth and elevation by
+// time-aligning the channels with fractional delays and averaging them.
+func (a Array) DelayAndSum(x Frames, fS, az, el float64) DataSet {
+	delays := a.Delays(az, el)
+	n := NextPow2(x.Len())
+	sum := make([]complex128, n)
+	for c, ch := range x.Split() {
+		X := realToComplex(ch, n)
+		fft(X, false)
+		for k := range X {
+			f := float64(k) * fS / float64(n)
+			if k > n/2 {
+				f -= fS
+			}
+			sum[k] += X[k] * cmplx.Rect(1, 2*math.Pi*f*delays[c])
+		}
+	}
+	fft(sum, true)
+
+	out := make([]float64, x.Len())
+	for i := range out {
+		out[i] = real(sum[i]) / float64(len(delays))
+	}
+	return out
+}
+
+// MVDR steers the array towards the given azimuth and elevation using
+// per-frequency MVDR weights estimated from STFT frames of frameSize samples,
+// which must be at least 4 so the frames can overlap by three quarters.
+func (a Array) MVDR(x Frames, fS, az, el float64, frameSize int) DataSet {
+	hop := frameSize / 4
+	if frameSize <= 0 || hop <= 0 {
+		log.Fatalf("Array.MVDR requires a frame size of at least 4, got %d", frameSize)
+	}
+	window := Hann(frameSize)
+	var spectra [][][]complex128
+	for _, ch := range x.Split() {
+		spectra = append(spectra, stft(ch, frameSize, hop, window))
+	}
+	if len(spectra) == 0 || len(spectra[0]) == 0 {
+		return DataSet{}
+	}
+
+	frames := len(spectra[0])
+	bins := len(spectra[0][0])
+	nfft := NextPow2(frameSize)
+	out := make([][]complex128, frames)
+	for t := range out {
+		out[t] = make([]complex128, bins)
+	}
+	snapshots := make([][]complex128, frames)
+	for k := 0; k < bins; k++ {
+		for t := 0; t < frames; t++ {
+			s := make([]complex128, len(spectra))
+			for c := range spectra {
+				s[c] = spectra[c][t][k]
+			}
+			snapshots[t] = s
+		}
+		sv := a.SteeringVector(az, el, float64(k)*fS/float64(nfft))
+		w := mvdrWeights(covariance(snapshots), sv)
+		for t := 0; t < frames; t++ {
+			out[t][k] = cdot(w, snapshots[t])
+		}
+	}
+	return istft(out, frameSize, hop, window, x.Len())
+}
+
+// SpatialSpectrum returns the narrowband output power at frequency f for a
+// beam steered to each azimuth, at a fixed elevation. The snapshots are taken
+// from Hann windowed segments of segLen samples.
+func (a Array) SpatialSpectrum(x Frames, f, fS float64, segLen int, azimuths DataSet, el float64, method BeamformMethod) DataSet {
+	R := covariance(Snapshots(x, f, fS, segLen))
+	var Rinv [][]complex128
+	if method == MVDRMethod {
+		Rinv = cmatInverse(diagonalLoad(R, mvdrLoading))
+	}
+
+	m := float64(len(a.Positions))
+	power := make([]float64, len(azimuths))
+	for i, az := range azimuths {
+		sv := a.SteeringVector(az, el, f)
+		if method == MVDRMethod {
+			power[i] = 1 / real(cdot(sv, cmatVec(Rinv, sv)))
+		} else {
+			power[i] = real(cdot(sv, cmatVec(R, sv))) / (m * m)
+		}
+	}
+	return power
+}
+
+// Snapshots returns the narrowband array snapshots at frequency f, one per
+// Hann windowed segment of segLen samples overlapping by half a segment.
+func Snapshots(x Frames, f, fS float64, segLen int) [][]complex128 {
+	window := Hann(segLen)
+	step := segLen / 2
+	if step < 1 {
+		step = 1
+	}
+	var snapshots [][]complex128
+	for start := 0; start+segLen <= x.Len(); start += step {
+		s := make([]complex128, x.Channels())
+		for i := 0; i < segLen; i++ {
+			w := cmplx.Rect(window[i], -2*math.Pi*f*float64(i)/fS)
+			for c, v := range x[start+i] {
+				s[c] += complex(v, 0) * w
+			}
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots
+}
+
+// mvdrWeights returns R^-1 a / (a^H R^-1 a) for the loaded covariance R.
+func mvdrWeights(R [][]complex128, sv []complex128) []complex128 {
+	Rinv := cmatInverse(diagonalLoad(R, mvdrLoading))
+	if Rinv == nil {
+		w := make([]complex128, len(sv))
+		for i := range w {
+			w[i] = sv[i] / complex(float64(len(sv)), 0)
+		}
+		return w
+	}
+	num := cmatVec(Rinv, sv)
+	den := cdot(sv, num)
+	for i := range num {
+		num[i] /= cmplx.Conj(den)
+	}
+	return num
+}
@@ -0,0 +1,112 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Block is a block of stream samples with the metadata needed to place them
+// in absolute time, so that events detected in the samples can be reported
+// with accurate timestamps after any number of processing stages.
+type Block struct {
+	// Index is the position in the stream of the first sample, counted at
+	// the block's sample rate and including dropped samples.
+	Index int
+
+	// Time is the wall-clock time of the first sample.
+	Time time.Time
+
+	// SampleRate is the sample rate of the block in Hz.
+	SampleRate float64
+
+	// Dropped is the number of samples lost immediately before the block.
+	Dropped int
+
+	Data DataSet
+}
+
+// SampleTime returns the wall-clock time of sample i of the block.
+func (b Block) SampleTime(i int) time.Time {
+	return b.Time.Add(secondsDuration(float64(i) / b.SampleRate))
+}
+
+// TimeAt returns the wall-clock time of a fractional sample position in the
+// block, such as a refined peak or edge.
+func (b Block) TimeAt(pos float64) time.Time {
+	return b.Time.Add(secondsDuration(pos / b.SampleRate))
+}
+
+// BlockClock stamps the raw blocks of a stream with their metadata.
+type BlockClock struct {
+	// SampleRate is the sample rate of the stream in Hz.
+	SampleRate float64
+
+	// Start is the wall-clock time of the first sample.
+	Start time.Time
+
+	index int
+}
+
+// NewBlockClock creates a clock for a stream starting at the given time.
+func NewBlockClock(fS float64, start time.Time) *BlockClock {
+	return &BlockClock{SampleRate: fS, Start: start}
+}
+
+// Stamp wraps the next block of the stream, after the given number of
+// dropped samples, with its metadata. The time is derived from the start time
+// and the sample count; set it from a hardware timestamp where one is
+// available.
+func (c *BlockClock) Stamp(X []float64, dropped int) Block {
+	c.index += dropped
+	b := Block{
+		Index:      c.index,
+		Time:       c.Start.Add(secondsDuration(float64(c.index) / c.SampleRate)),
+		SampleRate: c.SampleRate,
+		Dropped:    dropped,
+		Data:       X,
+	}
+	c.index += len(X)
+	return b
+}
+
+// Reset restarts the sample count.
+func (c *BlockClock) Reset() {
+	c.index = 0
+}
+
+// ProcessBlock runs a block through a processor and returns the output with
+// its metadata carried over. For a RateChanger the index, sample rate and
+// dropped count are converted to the output rate, and the time is moved to
+// the first output sample, which for a decimator may lie after the first
+// input sample. Dropped samples are first passed to a Skipper so that its
+// output phase stays locked to the stream index; the indices are only exact
+// if the processor has seen the stream from index 0. The delay of the
+// processor's filters is not removed.
+func ProcessBlock(p Processor, b Block) Block {
+	out := b
+	dropped := b.Dropped
+	if s, ok := p.(Skipper); ok && b.Dropped > 0 {
+		dropped = s.Skip(b.Dropped)
+	}
+	out.Data = p.Process(b.Data)
+	r, ok := p.(RateChanger)
+	if !ok {
+		return out
+	}
+	up, down := r.RateRatio()
+	out.SampleRate = b.SampleRate * float64(up) / float64(down)
+	out.Index = (b.Index*up + down - 1) / down
+	out.Dropped = dropped
+	if _, ok := p.(Skipper); !ok {
+		out.Dropped = b.Dropped * up / down
+	}
+	offset := float64(out.Index*down)/float64(up) - float64(b.Index)
+	out.Time = b.TimeAt(offset)
+	return out
+}
+
+// secondsDuration converts seconds to a duration, rounded to the nearest
+// nanosecond.
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
@@ -0,0 +1,80 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// AmbiguitySurface is the magnitude of a cross-ambiguity function over a grid
+// of delays (in samples) and Doppler shifts (in Hz).
+type AmbiguitySurface struct {
+	Delays   []int
+	Dopplers DataSet
+
+	// Values is indexed by Doppler then delay.
+	Values [][]float64
+}
+
+// Peak returns the delay, Doppler shift and magnitude of the surface maximum.
+func (a *AmbiguitySurface) Peak() (int, float64, float64) {
+	var delay int
+	var doppler float64
+	peak := math.Inf(-1)
+	for i, row := range a.Values {
+		for j, v := range row {
+			if v > peak {
+				peak = v
+				delay = a.Delays[j]
+				doppler = a.Dopplers[i]
+			}
+		}
+	}
+	return delay, doppler, peak
+}
+
+// CrossAmbiguity computes the cross-ambiguity function between a reference
+// and a surveillance signal for delays 0 through maxDelay samples and each of
+// the given Doppler shifts. A target echo appears as a peak at its bistatic
+// delay and Doppler.
+func CrossAmbiguity(ref, surv IQ, maxDelay int, dopplers DataSet, fS float64) *AmbiguitySurface {
+	n := len(surv)
+	if len(ref) > n {
+		n = len(ref)
+	}
+	size := NextPow2(n + maxDelay)
+
+	R := make([]complex128, size)
+	copy(R, ref)
+	fft(R, false)
+
+	surface := &AmbiguitySurface{
+		Delays:   make([]int, maxDelay+1),
+		Dopplers: dopplers,
+		Values:   make([][]float64, len(dopplers)),
+	}
+	for j := range surface.Delays {
+		surface.Delays[j] = j
+	}
+
+	Y := make([]complex128, size)
+	for i, fd := range dopplers {
+		for k := range Y {
+			Y[k] = 0
+		}
+		for k, v := range surv {
+			Y[k] = v * cmplx.Rect(1, -2*math.Pi*fd*float64(k)/fS)
+		}
+		fft(Y, false)
+		for k := range Y {
+			Y[k] *= cmplx.Conj(R[k])
+		}
+		fft(Y, true)
+
+		row := make([]float64, maxDelay+1)
+		for j := range row {
+			row[j] = cmplx.Abs(Y[j])
+		}
+		surface.Values[i] = row
+	}
+	return surface
+}
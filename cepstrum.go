@@ -0,0 +1,116 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// cepstrumFloor keeps the log spectrum finite at bins with zero magnitude.
+const cepstrumFloor = 1e-300
+
+// Cepstrum returns the real cepstrum of the data set, the inverse FFT of its
+// log magnitude spectrum. Echoes and pitch periods show up as peaks at their
+// delay in samples (quefrency). The data set is zero padded to a power of two
+// and the result has the padded length.
+func (d DataSet) Cepstrum() DataSet {
+	n := NextPow2(len(d))
+	X := realToComplex(d, n)
+	fft(X, false)
+	for k, v := range X {
+		X[k] = complex(math.Log(math.Max(cmplx.Abs(v), cepstrumFloor)), 0)
+	}
+	fft(X, true)
+	values := make([]float64, n)
+	for i, v := range X {
+		values[i] = real(v)
+	}
+	return values
+}
+
+// ComplexCepstrum returns the complex cepstrum of the data set, the inverse
+// FFT of its log magnitude and unwrapped phase, along with the delay in
+// samples that was removed from the phase to make it continuous. Unlike the
+// real cepstrum it keeps the phase, so the signal can be rebuilt with
+// InverseComplexCepstrum after liftering. The data set is zero padded to a
+// power of two and the result has the padded length.
+func (d DataSet) ComplexCepstrum() (DataSet, int) {
+	n := NextPow2(len(d))
+	X := realToComplex(d, n)
+	fft(X, false)
+
+	half := n/2 + 1
+	phase := make([]float64, half)
+	for k := range phase {
+		phase[k] = cmplx.Phase(X[k])
+	}
+	phase = Unwrap(phase)
+
+	// remove the linear phase term so the phase is zero at the Nyquist bin
+	delay := 0
+	if n > 1 {
+		delay = int(math.Round(phase[half-1] / math.Pi))
+		for k := range phase {
+			phase[k] -= math.Pi * float64(delay*k) / float64(n/2)
+		}
+	}
+
+	logX := make([]complex128, half)
+	for k := range logX {
+		logX[k] = complex(math.Log(math.Max(cmplx.Abs(X[k]), cepstrumFloor)), phase[k])
+	}
+	return irfft(logX, n), delay
+}
+
+// InverseComplexCepstrum rebuilds a signal from its complex cepstrum and the
+// delay returned by ComplexCepstrum.
+func InverseComplexCepstrum(c DataSet, delay int) DataSet {
+	n := len(c)
+	X := realToComplex(c, n)
+	if isPow2(n) {
+		fft(X, false)
+	} else {
+		X = dft(X, false)
+	}
+
+	half := make([]complex128, n/2+1)
+	for k := range half {
+		phase := imag(X[k])
+		if n > 1 {
+			phase += math.Pi * float64(delay*k) / float64(n/2)
+		}
+		half[k] = cmplx.Exp(complex(real(X[k]), phase))
+	}
+	return irfft(half, n)
+}
+
+// MinimumPhase returns the minimum phase equivalent of an FIR filter or
+// impulse response: the causal sequence with the same magnitude response and
+// the least possible delay, found by folding the real cepstrum onto positive
+// quefrencies. The transform is padded well beyond the data set to limit
+// cepstral aliasing, and the result is truncated to the original length.
+func (d DataSet) MinimumPhase() DataSet {
+	n := NextPow2(8 * len(d))
+	padded := make(DataSet, n)
+	copy(padded, d)
+	c := padded.Cepstrum()
+
+	folded := make([]complex128, n)
+	folded[0] = complex(c[0], 0)
+	for i := 1; i < n/2; i++ {
+		folded[i] = complex(2*c[i], 0)
+	}
+	if n > 1 {
+		folded[n/2] = complex(c[n/2], 0)
+	}
+	fft(folded, false)
+	for k, v := range folded {
+		folded[k] = cmplx.Exp(v)
+	}
+	fft(folded, true)
+
+	values := make([]float64, len(d))
+	for i := range values {
+		values[i] = real(folded[i])
+	}
+	return values
+}
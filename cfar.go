@@ -0,0 +1,95 @@
+package dsp
+
+import "math"
+
+// Detection is a target reported by a detector.
+type Detection struct {
+	// Index is the cell of the detection peak.
+	Index int
+
+	// Value is the power in the cell.
+	Value float64
+
+	// Noise is the estimated noise power around the cell.
+	Noise float64
+
+	// SNR is the ratio of Value to Noise in dB.
+	SNR float64
+}
+
+// CFARThreshold returns the cell-averaging CFAR detection threshold for every
+// cell of power (square law) data, such as a power spectrum or the squared
+// output of a matched filter. The noise in each cell is estimated as the mean
+// of train cells on each side, skipping guard cells next to it so a target
+// does not raise its own threshold, and scaled to give a probability of false
+// alarm pfa in exponentially distributed noise. Near the ends the cells that
+// exist on either side are used.
+func (d DataSet) CFARThreshold(guard, train int, pfa float64) DataSet {
+	threshold := make([]float64, len(d))
+	for i := range d {
+		noise, n := d.cfarNoise(i, guard, train)
+		if n == 0 {
+			threshold[i] = math.Inf(1)
+			continue
+		}
+		threshold[i] = noise * cfarScale(n, pfa)
+	}
+	return threshold
+}
+
+// CFAR runs a cell-averaging CFAR detector over power data and returns one
+// detection at the peak of each run of cells above the threshold.
+func (d DataSet) CFAR(guard, train int, pfa float64) []Detection {
+	threshold := d.CFARThreshold(guard, train, pfa)
+	var detections []Detection
+	for i := 0; i < len(d); {
+		if d[i] <= threshold[i] {
+			i++
+			continue
+		}
+		peak := i
+		for ; i < len(d) && d[i] > threshold[i]; i++ {
+			if d[i] > d[peak] {
+				peak = i
+			}
+		}
+		noise, _ := d.cfarNoise(peak, guard, train)
+		detections = append(detections, Detection{
+			Index: peak,
+			Value: d[peak],
+			Noise: noise,
+			SNR:   10 * math.Log10(d[peak]/noise),
+		})
+	}
+	return detections
+}
+
+// cfarNoise returns the mean of the training cells around cell i and how many
+// there were.
+func (d DataSet) cfarNoise(i, guard, train int) (float64, int) {
+	var sum float64
+	n := 0
+	for j := i - guard - train; j < i-guard; j++ {
+		if j >= 0 {
+			sum += d[j]
+			n++
+		}
+	}
+	for j := i + guard + 1; j <= i+guard+train; j++ {
+		if j < len(d) {
+			sum += d[j]
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return sum / float64(n), n
+}
+
+// cfarScale returns the threshold multiplier giving a false alarm probability
+// pfa when the noise is averaged over n exponentially distributed cells.
+func cfarScale(n int, pfa float64) float64 {
+	N := float64(n)
+	return N * (math.Pow(pfa, -1/N) - 1)
+}
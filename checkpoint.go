@@ -0,0 +1,694 @@
+package dsp
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// Checkpointer is implemented by processors whose internal state can be saved
+// and later restored, so that a long-running stream can be stopped and
+// resumed without the transient of starting from a cleared state. State is
+// restored into a processor constructed with the same parameters as the one
+// that saved it.
+type Checkpointer interface {
+	// SaveState writes the internal state to w.
+	SaveState(w io.Writer) error
+
+	// LoadState replaces the internal state with one read from r.
+	LoadState(r io.Reader) error
+}
+
+// streamFilterState is the saved state of a StreamFilter.
+type streamFilterState struct {
+	F, Target Filter
+	Z, DA, DB []float64
+	Remaining int
+	PerBlock  bool
+}
+
+// SaveState writes the coefficients, delays and any ramp in progress.
+func (s *StreamFilter) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(s.state())
+}
+
+// LoadState restores the coefficients, delays and any ramp in progress.
+func (s *StreamFilter) LoadState(r io.Reader) error {
+	var st streamFilterState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	s.restore(st)
+	return nil
+}
+
+// state returns the saved state of the filter.
+func (s *StreamFilter) state() streamFilterState {
+	return streamFilterState{
+		F: s.f, Target: s.target, Z: s.z, DA: s.dA, DB: s.dB,
+		Remaining: s.remaining, PerBlock: s.perBlock,
+	}
+}
+
+// restore replaces the filter state with a saved one.
+func (s *StreamFilter) restore(st streamFilterState) {
+	s.f, s.target, s.z, s.dA, s.dB = st.F, st.Target, st.Z, st.DA, st.DB
+	s.remaining, s.perBlock = st.Remaining, st.PerBlock
+}
+
+// ringBufferState is the saved state of a RingBuffer.
+type ringBufferState struct {
+	Data        []float64
+	Start, Size int
+}
+
+// SaveState writes the buffered samples.
+func (r *RingBuffer) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(r.state())
+}
+
+// LoadState restores the buffered samples.
+func (r *RingBuffer) LoadState(rd io.Reader) error {
+	var st ringBufferState
+	if err := gob.NewDecoder(rd).Decode(&st); err != nil {
+		return err
+	}
+	r.restore(st)
+	return nil
+}
+
+// state returns the saved state of the buffer.
+func (r *RingBuffer) state() ringBufferState {
+	return ringBufferState{Data: r.data, Start: r.start, Size: r.size}
+}
+
+// restore replaces the buffer contents with saved ones. The capacity is
+// kept, since a buffer saved empty may encode no data.
+func (r *RingBuffer) restore(st ringBufferState) {
+	data := make([]float64, len(r.data))
+	copy(data, st.Data)
+	r.data, r.start, r.size = data, st.Start, st.Size
+}
+
+// normalizerState is the saved state of a Normalizer.
+type normalizerState struct {
+	Weight, Mean, SSQ float64
+}
+
+// SaveState writes the running statistics.
+func (n *Normalizer) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(normalizerState{n.weight, n.mean, n.ssq})
+}
+
+// LoadState restores the running statistics.
+func (n *Normalizer) LoadState(r io.Reader) error {
+	var st normalizerState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	n.weight, n.mean, n.ssq = st.Weight, st.Mean, st.SSQ
+	return nil
+}
+
+// goertzelState is the saved state of a Goertzel filter.
+type goertzelState struct {
+	S1, S2 float64
+	N      int
+}
+
+// SaveState writes the filter state.
+func (g *Goertzel) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(goertzelState{g.s1, g.s2, g.n})
+}
+
+// LoadState restores the filter state.
+func (g *Goertzel) LoadState(r io.Reader) error {
+	var st goertzelState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	g.s1, g.s2, g.n = st.S1, st.S2, st.N
+	return nil
+}
+
+// decimatorState is the saved state of a Decimator.
+type decimatorState struct {
+	History []float64
+	Skip    int
+}
+
+// SaveState writes the filter history and output phase.
+func (d *Decimator) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(decimatorState{d.history, d.skip})
+}
+
+// LoadState restores the filter history and output phase.
+func (d *Decimator) LoadState(r io.Reader) error {
+	var st decimatorState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	d.history, d.skip = st.History, st.Skip
+	return nil
+}
+
+// SaveState writes the filter history.
+func (p *Interpolator) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(p.history)
+}
+
+// LoadState restores the filter history.
+func (p *Interpolator) LoadState(r io.Reader) error {
+	return gob.NewDecoder(r).Decode(&p.history)
+}
+
+// stateSpaceState is the saved state of a StateSpace system.
+type stateSpaceState struct {
+	State []float64
+}
+
+// SaveState writes the state vector.
+func (s *StateSpace) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(stateSpaceState{s.state})
+}
+
+// LoadState restores the state vector.
+func (s *StateSpace) LoadState(r io.Reader) error {
+	var st stateSpaceState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	s.state = st.State
+	return nil
+}
+
+// latticeState is the saved state of a Lattice or LatticeLadder.
+type latticeState struct {
+	B []float64
+}
+
+// SaveState writes the backward errors.
+func (l *Lattice) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(latticeState{l.b})
+}
+
+// LoadState restores the backward errors.
+func (l *Lattice) LoadState(r io.Reader) error {
+	var st latticeState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	l.b = st.B
+	return nil
+}
+
+// SaveState writes the backward errors.
+func (l *LatticeLadder) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(latticeState{l.b})
+}
+
+// LoadState restores the backward errors.
+func (l *LatticeLadder) LoadState(r io.Reader) error {
+	var st latticeState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	l.b = st.B
+	return nil
+}
+
+// slidingDFTState is the saved state of a SlidingDFT.
+type slidingDFTState struct {
+	Values  []complex128
+	History []float64
+	Pos     int
+}
+
+// SaveState writes the bin values and the sample window.
+func (s *SlidingDFT) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(slidingDFTState{s.values, s.history, s.pos})
+}
+
+// LoadState restores the bin values and the sample window.
+func (s *SlidingDFT) LoadState(r io.Reader) error {
+	var st slidingDFTState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	copy(s.values, st.Values)
+	copy(s.history, st.History)
+	s.pos = st.Pos
+	return nil
+}
+
+// historyState is the saved state of a processor whose only state is its
+// input history.
+type historyState struct {
+	History []float64
+}
+
+// SaveState writes the filter history.
+func (a *AnalyticFilter) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(historyState{a.history})
+}
+
+// LoadState restores the filter history.
+func (a *AnalyticFilter) LoadState(r io.Reader) error {
+	return loadHistory(r, a.history)
+}
+
+// SaveState writes the filter history.
+func (f *FIRFilter) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(historyState{f.activeHistory()})
+}
+
+// LoadState restores the filter history.
+func (f *FIRFilter) LoadState(r io.Reader) error {
+	return loadHistory(r, f.activeHistory())
+}
+
+// activeHistory returns the history of the convolution in use.
+func (f *FIRFilter) activeHistory() []float64 {
+	if f.fast != nil {
+		return f.fast.history
+	}
+	return f.history
+}
+
+// SaveState writes the filter history.
+func (f *OverlapSaveFilter) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(historyState{f.history})
+}
+
+// LoadState restores the filter history.
+func (f *OverlapSaveFilter) LoadState(r io.Reader) error {
+	return loadHistory(r, f.history)
+}
+
+// triggerState is the saved state of a Trigger.
+type triggerState struct {
+	History            ringBufferState
+	Pending            []Capture
+	Index, Wait        int
+	ArmedUp, ArmedDown bool
+}
+
+// SaveState writes the pre-trigger samples, the captures in progress, the
+// sample count and the arming and holdoff state.
+func (t *Trigger) SaveState(w io.Writer) error {
+	st := triggerState{
+		History: t.history.state(),
+		Index:   t.index, Wait: t.wait,
+		ArmedUp: t.armedUp, ArmedDown: t.armedDown,
+	}
+	for _, c := range t.pending {
+		st.Pending = append(st.Pending, *c)
+	}
+	return gob.NewEncoder(w).Encode(st)
+}
+
+// LoadState restores the pre-trigger samples, the captures in progress, the
+// sample count and the arming and holdoff state.
+func (t *Trigger) LoadState(r io.Reader) error {
+	var st triggerState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	t.history = NewRingBuffer(t.PreTrigger)
+	t.history.restore(st.History)
+	t.pending = nil
+	for i := range st.Pending {
+		t.pending = append(t.pending, &st.Pending[i])
+	}
+	t.index, t.wait = st.Index, st.Wait
+	t.armedUp, t.armedDown = st.ArmedUp, st.ArmedDown
+	return nil
+}
+
+// blockClockState is the saved state of a BlockClock.
+type blockClockState struct {
+	Index int
+}
+
+// SaveState writes the sample count.
+func (c *BlockClock) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(blockClockState{c.index})
+}
+
+// LoadState restores the sample count.
+func (c *BlockClock) LoadState(r io.Reader) error {
+	var st blockClockState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	c.index = st.Index
+	return nil
+}
+
+// driftCorrectorState is the saved state of a DriftCorrector.
+type driftCorrectorState struct {
+	Rate     float64
+	Buf      []float64
+	Position float64
+}
+
+// SaveState writes the buffered input, the output position and the drift
+// rate, which may have been updated while tracking.
+func (c *DriftCorrector) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(driftCorrectorState{c.Rate, c.buf, c.pos})
+}
+
+// LoadState restores the buffered input, the output position and the drift
+// rate.
+func (c *DriftCorrector) LoadState(r io.Reader) error {
+	var st driftCorrectorState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	c.Rate, c.buf, c.pos = st.Rate, st.Buf, st.Position
+	return nil
+}
+
+// nlmsState is the saved state of an NLMSCanceller.
+type nlmsState struct {
+	Weights, History []float64
+	Power            float64
+}
+
+// SaveState writes the adapted weights, reference history and power.
+func (c *NLMSCanceller) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(nlmsState{c.weights, c.history, c.power})
+}
+
+// LoadState restores the adapted weights, reference history and power, so
+// that cancellation resumes without converging again.
+func (c *NLMSCanceller) LoadState(r io.Reader) error {
+	var st nlmsState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	copy(c.weights, st.Weights)
+	copy(c.history, st.History)
+	c.power = st.Power
+	return nil
+}
+
+// bandProjectorState is the saved state of a BandProjector.
+type bandProjectorState struct {
+	HP, LP  streamFilterState
+	History []float64
+}
+
+// SaveState writes the band filter states and the reference history.
+func (p *BandProjector) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(bandProjectorState{p.hp.state(), p.lp.state(), p.history})
+}
+
+// LoadState restores the band filter states and the reference history.
+func (p *BandProjector) LoadState(r io.Reader) error {
+	var st bandProjectorState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	p.hp.restore(st.HP)
+	p.lp.restore(st.LP)
+	copy(p.history, st.History)
+	return nil
+}
+
+// averagerState is the saved state of a SpectrumAverager.
+type averagerState struct {
+	Avg, Sum   []float64
+	History    [][]float64
+	Pos, Count int
+}
+
+// SaveState writes the running average and the spectra it holds.
+func (a *SpectrumAverager) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(a.state())
+}
+
+// LoadState restores the running average and the spectra it holds.
+func (a *SpectrumAverager) LoadState(r io.Reader) error {
+	var st averagerState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	a.restore(st)
+	return nil
+}
+
+// state returns the saved state of the averager.
+func (a *SpectrumAverager) state() averagerState {
+	return averagerState{a.avg, a.sum, a.history, a.pos, a.count}
+}
+
+// restore replaces the averager state with a saved one.
+func (a *SpectrumAverager) restore(st averagerState) {
+	a.avg, a.sum, a.history, a.pos, a.count = st.Avg, st.Sum, st.History, st.Pos, st.Count
+}
+
+// minimumStatisticsState is the saved state of a MinimumStatistics tracker.
+type minimumStatisticsState struct {
+	Smoothed []float64
+	History  [][]float64
+	Pos      int
+}
+
+// SaveState writes the smoothed spectrum and the window of past spectra.
+func (m *MinimumStatistics) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(minimumStatisticsState{m.smoothed, m.history, m.pos})
+}
+
+// LoadState restores the smoothed spectrum and the window of past spectra.
+func (m *MinimumStatistics) LoadState(r io.Reader) error {
+	var st minimumStatisticsState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	m.smoothed, m.history, m.pos = st.Smoothed, st.History, st.Pos
+	return nil
+}
+
+// analyzerState is the saved state of an Analyzer.
+type analyzerState struct {
+	Buffer   ringBufferState
+	Averager averagerState
+	Pending  int
+	Spectrum DataSet
+}
+
+// SaveState writes the buffered samples, the spectrum average and the
+// position within the hop.
+func (a *Analyzer) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(analyzerState{a.buf.state(), a.avg.state(), a.pending, a.spectrum})
+}
+
+// LoadState restores the buffered samples, the spectrum average and the
+// position within the hop.
+func (a *Analyzer) LoadState(r io.Reader) error {
+	var st analyzerState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	a.buf.restore(st.Buffer)
+	a.avg.restore(st.Averager)
+	a.pending, a.spectrum = st.Pending, st.Spectrum
+	return nil
+}
+
+// SaveState writes the state of every stage.
+func (c *HalfBandCascade) SaveState(w io.Writer) error {
+	return saveStages(w, c.stages)
+}
+
+// LoadState restores the state of every stage.
+func (c *HalfBandCascade) LoadState(r io.Reader) error {
+	return loadStages(r, c.stages)
+}
+
+// SaveState writes the state of every stage. It fails if a stage is not a
+// Checkpointer.
+func (p Pipeline) SaveState(w io.Writer) error {
+	return saveStages(w, p)
+}
+
+// LoadState restores the state of every stage.
+func (p Pipeline) LoadState(r io.Reader) error {
+	return loadStages(r, p)
+}
+
+// equalizerState is the saved state of an Equalizer.
+type equalizerState struct {
+	Bands  []EQBand
+	Stages [][]byte
+}
+
+// SaveState writes the band settings and the state of each band's filter.
+func (e *Equalizer) SaveState(w io.Writer) error {
+	stages, err := stageStates(e.filterStages())
+	if err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(equalizerState{Bands: e.bands, Stages: stages})
+}
+
+// LoadState restores the band settings and the state of each band's filter.
+func (e *Equalizer) LoadState(r io.Reader) error {
+	var st equalizerState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	e.bands = nil
+	e.filters = nil
+	for _, b := range st.Bands {
+		e.AddBand(b)
+	}
+	return loadStageStates(st.Stages, e.filterStages())
+}
+
+// filterStages returns the band filters as processors.
+func (e *Equalizer) filterStages() []Processor {
+	stages := make([]Processor, len(e.filters))
+	for i, f := range e.filters {
+		stages[i] = f
+	}
+	return stages
+}
+
+// tensorStageState is the saved state of a TensorStage.
+type tensorStageState struct {
+	Pending []float64
+}
+
+// SaveState writes the samples waiting for a full batch. A model error is
+// not saved.
+func (s *TensorStage) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(tensorStageState{s.pending})
+}
+
+// LoadState restores the samples waiting for a full batch.
+func (s *TensorStage) LoadState(r io.Reader) error {
+	var st tensorStageState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	s.pending, s.err = st.Pending, nil
+	return nil
+}
+
+// graphState is the saved state of a Graph.
+type graphState struct {
+	Stages  [][]byte
+	Pending [][][]float64
+}
+
+// SaveState writes the state of every processor and the samples waiting in
+// each mixer. It fails if a processor is not a Checkpointer.
+func (g *Graph) SaveState(w io.Writer) error {
+	stages, err := stageStates(g.processors())
+	if err != nil {
+		return err
+	}
+	st := graphState{Stages: stages, Pending: make([][][]float64, len(g.nodes))}
+	for i, node := range g.nodes {
+		st.Pending[i] = node.pending
+	}
+	return gob.NewEncoder(w).Encode(st)
+}
+
+// LoadState restores the state of every processor and mixer.
+func (g *Graph) LoadState(r io.Reader) error {
+	var st graphState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	if len(st.Pending) != len(g.nodes) {
+		return fmt.Errorf("dsp: checkpoint has %d nodes, want %d", len(st.Pending), len(g.nodes))
+	}
+	for i, node := range g.nodes {
+		for j := range node.pending {
+			node.pending[j] = nil
+			if j < len(st.Pending[i]) {
+				node.pending[j] = st.Pending[i][j]
+			}
+		}
+	}
+	return loadStageStates(st.Stages, g.processors())
+}
+
+// processors returns the processors of the graph in node order.
+func (g *Graph) processors() []Processor {
+	var stages []Processor
+	for _, node := range g.nodes {
+		if node.proc != nil {
+			stages = append(stages, node.proc)
+		}
+	}
+	return stages
+}
+
+// saveStages writes the state of each stage. Each is encoded separately and
+// the results written as one value, since a gob decoder may read ahead of
+// the value it decodes.
+func saveStages(w io.Writer, stages []Processor) error {
+	states, err := stageStates(stages)
+	if err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(states)
+}
+
+// loadStages restores the state of each stage written by saveStages.
+func loadStages(r io.Reader, stages []Processor) error {
+	var states [][]byte
+	if err := gob.NewDecoder(r).Decode(&states); err != nil {
+		return err
+	}
+	return loadStageStates(states, stages)
+}
+
+// stageStates returns the encoded state of each stage.
+func stageStates(stages []Processor) ([][]byte, error) {
+	states := make([][]byte, len(stages))
+	for i, stage := range stages {
+		c, ok := stage.(Checkpointer)
+		if !ok {
+			return nil, fmt.Errorf("dsp: stage %d (%T) does not support checkpointing", i, stage)
+		}
+		var buf bytes.Buffer
+		if err := c.SaveState(&buf); err != nil {
+			return nil, err
+		}
+		states[i] = buf.Bytes()
+	}
+	return states, nil
+}
+
+// loadStageStates restores each stage from its encoded state.
+func loadStageStates(states [][]byte, stages []Processor) error {
+	if len(states) != len(stages) {
+		return fmt.Errorf("dsp: checkpoint has %d stages, want %d", len(states), len(stages))
+	}
+	for i, stage := range stages {
+		c, ok := stage.(Checkpointer)
+		if !ok {
+			return fmt.Errorf("dsp: stage %d (%T) does not support checkpointing", i, stage)
+		}
+		if err := c.LoadState(bytes.NewReader(states[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadHistory restores a history saved as a historyState into h.
+func loadHistory(r io.Reader, h []float64) error {
+	var st historyState
+	if err := gob.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	copy(h, st.History)
+	return nil
+}
@@ -0,0 +1,127 @@
+package dsp
+
+import (
+	"bufio"
+	"io"
+	"log"
+)
+
+// Flusher is implemented by processors that hold back samples, such as
+// TensorStage, and can emit them at the end of a stream.
+type Flusher interface {
+	Flush() []float64
+}
+
+// ChunkRunner streams a file of samples too large for memory through
+// processing a chunk at a time, writing the results as it goes.
+type ChunkRunner struct {
+	// ChunkSize is the number of input samples read at a time.
+	ChunkSize int
+
+	// Overlap is the number of samples of context added on each side of a
+	// chunk for RunFunc.
+	Overlap int
+
+	// Format is the encoding of the output samples.
+	Format SampleFormat
+}
+
+// NewChunkRunner creates a runner with the given chunk size, overlap and
+// output format. The chunk size must be positive and the overlap must not be
+// negative.
+func NewChunkRunner(chunkSize, overlap int, format SampleFormat) *ChunkRunner {
+	r := &ChunkRunner{ChunkSize: chunkSize, Overlap: overlap, Format: format}
+	r.check("NewChunkRunner")
+	return r
+}
+
+// check stops on a chunk size or overlap the runner cannot step through.
+func (r *ChunkRunner) check(name string) {
+	if r.ChunkSize <= 0 {
+		log.Fatalf("%s requires a positive chunk size, got %d", name, r.ChunkSize)
+	}
+	if r.Overlap < 0 {
+		log.Fatalf("%s requires a non-negative overlap, got %d", name, r.Overlap)
+	}
+}
+
+// Run resets the processor and streams every sample of src through it in
+// chunks, writing its output to w. The processor carries its own state from
+// chunk to chunk, so the output is the same as processing the whole file at
+// once. If the processor is a Flusher it is flushed at the end. It returns
+// the number of samples written.
+func (r *ChunkRunner) Run(src *MappedData, p Processor, w io.Writer) (int, error) {
+	r.check("ChunkRunner.Run")
+	p.Reset()
+	out := r.newWriter(w)
+	err := src.Chunks(r.ChunkSize, func(start int, chunk DataSet) error {
+		return out.write(p.Process(chunk))
+	})
+	if err != nil {
+		return out.n, err
+	}
+	if f, ok := p.(Flusher); ok {
+		if err := out.write(f.Flush()); err != nil {
+			return out.n, err
+		}
+	}
+	return out.n, out.flush()
+}
+
+// RunFunc applies a function that processes a whole block at once, such as
+// FiltFilt or an FFT based operation, to src in chunks, writing the result
+// to w. Each chunk is extended by up to Overlap samples of its neighbours on
+// both sides and only the output for the chunk itself is kept, so any effect
+// reaching less than Overlap samples is seamless across chunk boundaries and
+// the ends of the file are treated as in a single pass. fn must return as
+// many samples as it is given. It returns the number of samples written.
+func (r *ChunkRunner) RunFunc(src *MappedData, fn func(DataSet) DataSet, w io.Writer) (int, error) {
+	r.check("ChunkRunner.RunFunc")
+	out := r.newWriter(w)
+	block := make(DataSet, r.ChunkSize+2*r.Overlap)
+	for start := 0; start < src.Len(); start += r.ChunkSize {
+		size := minInt(r.ChunkSize, src.Len()-start)
+		lo := maxInt(start-r.Overlap, 0)
+		hi := minInt(start+size+r.Overlap, src.Len())
+		b := block[:hi-lo]
+		if err := src.decodeInto(b, lo); err != nil {
+			return out.n, err
+		}
+
+		y := fn(b)
+		if err := out.write(y[start-lo : start-lo+size]); err != nil {
+			return out.n, err
+		}
+	}
+	return out.n, out.flush()
+}
+
+// sampleWriter encodes samples to a buffered writer.
+type sampleWriter struct {
+	w      *bufio.Writer
+	format SampleFormat
+	buf    []byte
+	n      int
+}
+
+// newWriter creates a sample writer in the runner's output format.
+func (r *ChunkRunner) newWriter(w io.Writer) *sampleWriter {
+	return &sampleWriter{w: bufio.NewWriter(w), format: r.Format, buf: make([]byte, r.Format.Size())}
+}
+
+// write encodes and writes the samples.
+func (s *sampleWriter) write(samples []float64) error {
+	for _, v := range samples {
+		s.format.encode(s.buf, v)
+		if _, err := s.w.Write(s.buf); err != nil {
+			return err
+		}
+		s.n++
+	}
+	return nil
+}
+
+// flush writes any buffered output.
+func (s *sampleWriter) flush() error {
+	return s.w.Flush()
+}
@@ -0,0 +1,88 @@
+package dsp
+
+import "math"
+
+// CMVN applies per-utterance cepstral mean normalization to a feature matrix
+// with one feature vector per frame. If normVar is true each feature is also
+// scaled to unit variance. A new matrix is returned.
+func CMVN(features [][]float64, normVar bool) [][]float64 {
+	out := make([][]float64, len(features))
+	if len(features) == 0 {
+		return out
+	}
+	dim := len(features[0])
+	mean := make([]float64, dim)
+	ssq := make([]float64, dim)
+	for _, frame := range features {
+		for j := 0; j < dim; j++ {
+			mean[j] += frame[j]
+			ssq[j] += frame[j] * frame[j]
+		}
+	}
+	n := float64(len(features))
+	scale := make([]float64, dim)
+	for j := 0; j < dim; j++ {
+		mean[j] /= n
+		scale[j] = cmvnScale(ssq[j]/n-mean[j]*mean[j], normVar)
+	}
+
+	for i, frame := range features {
+		out[i] = make([]float64, dim)
+		for j := 0; j < dim; j++ {
+			out[i][j] = (frame[j] - mean[j]) * scale[j]
+		}
+	}
+	return out
+}
+
+// SlidingCMVN applies cepstral mean (and optionally variance) normalization
+// using statistics from a window of frames centered on each frame, which
+// suits streaming or long recordings where channel conditions drift.
+func SlidingCMVN(features [][]float64, window int, normVar bool) [][]float64 {
+	out := make([][]float64, len(features))
+	if len(features) == 0 {
+		return out
+	}
+	dim := len(features[0])
+
+	// cumulative sums make each window O(dim)
+	sum := make([][]float64, len(features)+1)
+	ssq := make([][]float64, len(features)+1)
+	sum[0] = make([]float64, dim)
+	ssq[0] = make([]float64, dim)
+	for i, frame := range features {
+		sum[i+1] = make([]float64, dim)
+		ssq[i+1] = make([]float64, dim)
+		for j := 0; j < dim; j++ {
+			sum[i+1][j] = sum[i][j] + frame[j]
+			ssq[i+1][j] = ssq[i][j] + frame[j]*frame[j]
+		}
+	}
+
+	half := window / 2
+	for i, frame := range features {
+		start, stop := i-half, i-half+window
+		if start < 0 {
+			start = 0
+		}
+		if stop > len(features) {
+			stop = len(features)
+		}
+		n := float64(stop - start)
+		out[i] = make([]float64, dim)
+		for j := 0; j < dim; j++ {
+			mean := (sum[stop][j] - sum[start][j]) / n
+			variance := (ssq[stop][j]-ssq[start][j])/n - mean*mean
+			out[i][j] = (frame[j] - mean) * cmvnScale(variance, normVar)
+		}
+	}
+	return out
+}
+
+// cmvnScale returns the scale factor for a feature with the given variance.
+func cmvnScale(variance float64, normVar bool) float64 {
+	if !normVar || variance <= 0 {
+		return 1
+	}
+	return 1 / math.Sqrt(variance)
+}
@@ -0,0 +1,161 @@
+package dsp
+
+// convolveDirectLimit is the kernel length above which convolution is
+// computed with the FFT rather than directly.
+const convolveDirectLimit = 64
+
+// fftKernel holds the transform of an FIR kernel for block convolution with
+// FFTs of a fixed size, four times the kernel length rounded up to a power
+// of two, which keeps the cost per output sample near its minimum.
+type fftKernel struct {
+	plan  *FFTPlan
+	h     []complex128
+	buf   []complex128
+	taps  int
+	block int
+}
+
+// newFFTKernel transforms the kernel for block convolution.
+func newFFTKernel(taps DataSet) *fftKernel {
+	n := NextPow2(4 * len(taps))
+	k := &fftKernel{
+		plan:  NewFFTPlan(n),
+		h:     realToComplex(taps, n),
+		buf:   make([]complex128, n),
+		taps:  len(taps),
+		block: n - len(taps) + 1,
+	}
+	k.plan.Forward(k.h, k.h)
+	return k
+}
+
+// circular computes the circular convolution of the kernel with src, zero
+// padded to the transform size, into k.buf.
+func (k *fftKernel) circular(src []float64) {
+	for i := range k.buf {
+		k.buf[i] = 0
+		if i < len(src) {
+			k.buf[i] = complex(src[i], 0)
+		}
+	}
+	k.plan.Forward(k.buf, k.buf)
+	for i := range k.buf {
+		k.buf[i] *= k.h[i]
+	}
+	k.plan.Inverse(k.buf, k.buf)
+}
+
+// Convolve returns the convolution of a and b, the part selected by mode.
+// The method is chosen from the lengths: direct convolution for short
+// kernels, overlap-add when one signal is much longer than the other, and a
+// single FFT of the full output otherwise.
+func Convolve(a, b DataSet, mode OutputMode) DataSet {
+	if len(a) == 0 || len(b) == 0 {
+		return DataSet{}
+	}
+	x, h := a, b
+	if len(h) > len(x) {
+		x, h = h, x
+	}
+
+	var full DataSet
+	switch {
+	case len(h) <= convolveDirectLimit:
+		full = make(DataSet, len(x)+len(h)-1)
+		for i, v := range x {
+			for j, c := range h {
+				full[i+j] += v * c
+			}
+		}
+	case len(x) > 8*len(h):
+		full = OverlapAdd(x, h)
+	default:
+		full = fftConvolve(x, h)
+	}
+	start, n := modeRange(len(a), len(b), mode)
+	return full[start : start+n]
+}
+
+// OverlapAdd returns the full convolution of a long signal with a shorter
+// kernel. The signal is split into blocks, each is convolved with the kernel
+// by FFT, and the overlapping tails of the results are added. When a Backend
+// other than CPUBackend is set, each block is convolved by Backend.Convolve.
+func OverlapAdd(x, h DataSet) DataSet {
+	if len(x) == 0 || len(h) == 0 {
+		return DataSet{}
+	}
+	k := newFFTKernel(h)
+	out := make(DataSet, len(x)+len(h)-1)
+	if offloaded() {
+		for start := 0; start < len(x); start += k.block {
+			y := backend.Convolve(x[start:minInt(start+k.block, len(x))], h)
+			for i, v := range y {
+				out[start+i] += v
+			}
+		}
+		return out
+	}
+	for start := 0; start < len(x); start += k.block {
+		k.circular(x[start:minInt(start+k.block, len(x))])
+		for i := 0; i < len(k.buf) && start+i < len(out); i++ {
+			out[start+i] += real(k.buf[i])
+		}
+	}
+	return out
+}
+
+// OverlapSaveFilter is a streaming FIR filter that convolves by FFT with the
+// overlap-save method, far cheaper than direct convolution for long kernels
+// such as room impulse responses. Each output depends only on past inputs,
+// so the output matches FIRFilter with no added latency and blocks can be of
+// any length. When a Backend other than CPUBackend is set, each block and the
+// history before it are convolved by Backend.Convolve.
+type OverlapSaveFilter struct {
+	Taps DataSet
+
+	kernel  *fftKernel
+	history []float64
+}
+
+// NewOverlapSaveFilter creates a streaming FFT convolver with the given taps.
+func NewOverlapSaveFilter(taps DataSet) *OverlapSaveFilter {
+	return &OverlapSaveFilter{
+		Taps:    taps,
+		kernel:  newFFTKernel(taps),
+		history: make([]float64, len(taps)-1),
+	}
+}
+
+// Process filters a block of samples, continuing from the state left by the
+// previous block.
+func (f *OverlapSaveFilter) Process(X []float64) []float64 {
+	h := len(f.history)
+	buf := make([]float64, h+len(X))
+	copy(buf, f.history)
+	copy(buf[h:], X)
+
+	Y := make([]float64, len(X))
+	if offloaded() {
+		copy(Y, backend.Convolve(buf, f.Taps)[h:])
+		copy(f.history, buf[len(buf)-h:])
+		return Y
+	}
+
+	// each transform yields block outputs after the h samples it wraps into
+	k := f.kernel
+	for start := 0; start < len(X); start += k.block {
+		k.circular(buf[start:minInt(start+len(k.buf), len(buf))])
+		for i := 0; i < k.block && start+i < len(Y); i++ {
+			Y[start+i] = real(k.buf[h+i])
+		}
+	}
+	copy(f.history, buf[len(buf)-h:])
+	return Y
+}
+
+// Reset clears the filter history.
+func (f *OverlapSaveFilter) Reset() {
+	for i := range f.history {
+		f.history[i] = 0
+	}
+}
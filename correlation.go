@@ -0,0 +1,237 @@
+package dsp
+
+// AutocorrelationFFTThreshold is the data set length above which the
+// autocorrelation is computed with the FFT rather than directly.
+var AutocorrelationFFTThreshold = 1024
+
+// Autocorrelation returns the raw autocorrelation of the data set for lags
+// 0 through maxLag. Long data sets are computed with the FFT (Wiener-Khinchin)
+// in O(N log N) time; short ones are computed directly.
+func (d DataSet) Autocorrelation(maxLag int) DataSet {
+	if maxLag >= len(d) {
+		maxLag = len(d) - 1
+	}
+	if maxLag < 0 {
+		return DataSet{}
+	}
+	if len(d) > AutocorrelationFFTThreshold {
+		return autocorrelationFFT(d, maxLag)
+	}
+	return autocorrelationDirect(d, maxLag)
+}
+
+// Normalization selects how correlation sums are scaled.
+type Normalization int
+
+const (
+	// NoNormalization returns the raw sums of lagged products.
+	NoNormalization Normalization = iota
+
+	// BiasedNormalization divides every lag by N. The estimate is biased
+	// toward zero at long lags but is always positive semi-definite, as AR
+	// modeling requires.
+	BiasedNormalization
+
+	// UnbiasedNormalization divides lag k by the N - k products it sums.
+	UnbiasedNormalization
+
+	// CoefficientNormalization divides by the zero lag value so the result
+	// is 1 at lag 0.
+	CoefficientNormalization
+)
+
+// NormalizedAutocorrelation returns the autocorrelation of the data set for
+// lags 0 through maxLag with the given normalization.
+func (d DataSet) NormalizedAutocorrelation(maxLag int, norm Normalization) DataSet {
+	return normalizeCorrelation(d.Autocorrelation(maxLag), len(d), 0, norm)
+}
+
+// AutocorrelationDirect returns the raw autocorrelation for lags 0 through
+// maxLag by summing lagged products, which is fastest for short data sets or
+// few lags.
+func (d DataSet) AutocorrelationDirect(maxLag int) DataSet {
+	if maxLag >= len(d) {
+		maxLag = len(d) - 1
+	}
+	if maxLag < 0 {
+		return DataSet{}
+	}
+	return autocorrelationDirect(d, maxLag)
+}
+
+// AutocorrelationFFT returns the raw autocorrelation for lags 0 through maxLag
+// from the inverse FFT of the power spectrum.
+func (d DataSet) AutocorrelationFFT(maxLag int) DataSet {
+	if maxLag >= len(d) {
+		maxLag = len(d) - 1
+	}
+	if maxLag < 0 {
+		return DataSet{}
+	}
+	return autocorrelationFFT(d, maxLag)
+}
+
+// normalizeCorrelation scales raw correlation sums of two signals of length n
+// in place. The lag of r[i] is i - zero.
+func normalizeCorrelation(r DataSet, n, zero int, norm Normalization) DataSet {
+	switch norm {
+	case BiasedNormalization:
+		for i := range r {
+			r[i] /= float64(n)
+		}
+	case UnbiasedNormalization:
+		for i := range r {
+			lag := i - zero
+			if lag < 0 {
+				lag = -lag
+			}
+			if lag < n {
+				r[i] /= float64(n - lag)
+			}
+		}
+	case CoefficientNormalization:
+		if zero >= 0 && zero < len(r) && r[zero] != 0 {
+			r0 := r[zero]
+			for i := range r {
+				r[i] /= r0
+			}
+		}
+	}
+	return r
+}
+
+// autocorrelationDirect computes the autocorrelation by summing lagged products.
+func autocorrelationDirect(d DataSet, maxLag int) DataSet {
+	r := make([]float64, maxLag+1)
+	for k := 0; k <= maxLag; k++ {
+		var sum float64
+		for i := k; i < len(d); i++ {
+			sum += d[i] * d[i-k]
+		}
+		r[k] = sum
+	}
+	return r
+}
+
+// autocorrelationFFT computes the autocorrelation as the inverse transform of
+// the power spectrum. The signal is zero-padded to avoid circular wrap-around.
+func autocorrelationFFT(d DataSet, maxLag int) DataSet {
+	n := NextPow2(2 * len(d))
+	X := realToComplex(d, n)
+	fft(X, false)
+	for i := range X {
+		re, im := real(X[i]), imag(X[i])
+		X[i] = complex(re*re+im*im, 0)
+	}
+	fft(X, true)
+
+	r := make([]float64, maxLag+1)
+	for k := 0; k <= maxLag; k++ {
+		r[k] = real(X[k])
+	}
+	return r
+}
+
+// OutputMode selects which part of a full convolution or correlation is
+// returned.
+type OutputMode int
+
+const (
+	// FullMode returns every lag at which the signals overlap, N + M - 1
+	// values.
+	FullMode OutputMode = iota
+
+	// SameMode returns the max(N, M) values centered on the full output.
+	SameMode
+
+	// ValidMode returns only the lags at which the shorter signal overlaps
+	// the longer one completely, max(N, M) - min(N, M) + 1 values.
+	ValidMode
+)
+
+// correlationDirectLimit is the length of the shorter signal above which
+// correlation is computed with the FFT.
+const correlationDirectLimit = 64
+
+// CrossCorrelate returns the cross-correlation c[k] = sum a[n+k] b[n] of a
+// and b. In FullMode the first value is at lag -(len(b)-1); use
+// CorrelationLags for the lag of every value. Long signals are correlated
+// with the FFT.
+func CrossCorrelate(a, b DataSet, mode OutputMode) DataSet {
+	if len(a) == 0 || len(b) == 0 {
+		return DataSet{}
+	}
+	rb := b.Reverse()
+	var full DataSet
+	if minInt(len(a), len(b)) > correlationDirectLimit {
+		full = fftConvolve(a, rb)
+	} else {
+		full = make([]float64, len(a)+len(b)-1)
+		for i, x := range a {
+			for j, y := range rb {
+				full[i+j] += x * y
+			}
+		}
+	}
+	start, n := modeRange(len(a), len(b), mode)
+	return full[start : start+n]
+}
+
+// CorrelationLags returns the lag of each value returned by CrossCorrelate
+// for signals of length n and m.
+func CorrelationLags(n, m int, mode OutputMode) []int {
+	start, count := modeRange(n, m, mode)
+	lags := make([]int, count)
+	for i := range lags {
+		lags[i] = start + i - (m - 1)
+	}
+	return lags
+}
+
+// CorrelationLag returns the lag at which a best matches b, so that a[n+lag]
+// is approximately b[n]; a positive lag means a is delayed relative to b.
+// The peak is refined to a fraction of a sample by parabolic interpolation.
+// Only lags up to maxLag in magnitude are searched, unless maxLag is
+// negative. It also returns the correlation at the peak.
+func CorrelationLag(a, b DataSet, maxLag int) (float64, float64) {
+	c := CrossCorrelate(a, b, FullMode)
+	if len(c) == 0 {
+		return 0, 0
+	}
+	zero := len(b) - 1
+	lo, hi := 0, len(c)-1
+	if maxLag >= 0 {
+		lo = maxInt(lo, zero-maxLag)
+		hi = minInt(hi, zero+maxLag)
+	}
+	best := lo
+	for i := lo; i <= hi; i++ {
+		if c[i] > c[best] {
+			best = i
+		}
+	}
+
+	offset := 0.0
+	if best > 0 && best < len(c)-1 {
+		y0, y1, y2 := c[best-1], c[best], c[best+1]
+		if denom := y0 - 2*y1 + y2; denom != 0 {
+			offset = 0.5 * (y0 - y2) / denom
+		}
+	}
+	return float64(best-zero) + offset, c[best]
+}
+
+// modeRange returns the start and length of the part of a full convolution
+// of signals of length n and m selected by mode.
+func modeRange(n, m int, mode OutputMode) (int, int) {
+	full := n + m - 1
+	switch mode {
+	case SameMode:
+		size := maxInt(n, m)
+		return (full - size) / 2, size
+	case ValidMode:
+		size := maxInt(n, m) - minInt(n, m) + 1
+		return minInt(n, m) - 1, size
+	}
+	return 0, full
+}
@@ -0,0 +1,80 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// ChirpZ computes m points of the chirp-Z transform of x,
+//
+//	X[k] = sum x[n] a^(-n) w^(nk)
+//
+// which evaluates the z-transform along the spiral z = a w^(-k). With a and w
+// on the unit circle this is a DFT over an arbitrary arc of frequencies at an
+// arbitrary spacing. It is computed as a convolution with power of two FFTs.
+func ChirpZ(x []complex128, m int, w, a complex128) []complex128 {
+	n := len(x)
+	if n == 0 || m <= 0 {
+		return make([]complex128, maxInt(m, 0))
+	}
+
+	// w^(j^2/2) for j = 0 .. max(n, m) - 1
+	size := maxInt(n, m)
+	chirp := make([]complex128, size)
+	for j := range chirp {
+		chirp[j] = spiralPow(w, float64(j)*float64(j)/2)
+	}
+
+	L := NextPow2(n + m - 1)
+	y := make([]complex128, L)
+	for i := 0; i < n; i++ {
+		y[i] = x[i] * spiralPow(a, -float64(i)) * chirp[i]
+	}
+	v := make([]complex128, L)
+	for j := 0; j < m; j++ {
+		v[j] = 1 / chirp[j]
+	}
+	for j := 1; j < n; j++ {
+		v[L-j] = 1 / chirp[j]
+	}
+
+	fft(y, false)
+	fft(v, false)
+	for i := range y {
+		y[i] *= v[i]
+	}
+	fft(y, true)
+
+	X := make([]complex128, m)
+	for k := range X {
+		X[k] = y[k] * chirp[k]
+	}
+	return X
+}
+
+// ZoomCZT returns m evenly spaced spectrum bins of the data set from f1 to
+// f2 inclusive, computed with the chirp-Z transform. Unlike a zero padded FFT
+// the cost depends on the number of output bins rather than the resolution,
+// and unlike ZoomFFT no filtering or decimation is involved. The resolution
+// is still limited by the length of the data.
+func (d DataSet) ZoomCZT(f1, f2 float64, m int, fS float64) (DataSet, []complex128) {
+	step := 0.0
+	if m > 1 {
+		step = (f2 - f1) / float64(m-1)
+	}
+	a := cmplx.Rect(1, 2*math.Pi*f1/fS)
+	w := cmplx.Rect(1, -2*math.Pi*step/fS)
+	bins := ChirpZ(realToComplex(d, len(d)), m, w, a)
+
+	freqs := make([]float64, m)
+	for k := range freqs {
+		freqs[k] = f1 + float64(k)*step
+	}
+	return freqs, bins
+}
+
+// spiralPow returns z^p for real p, taking the phase of z in (-pi, pi].
+func spiralPow(z complex128, p float64) complex128 {
+	r, theta := cmplx.Polar(z)
+	return cmplx.Rect(math.Pow(r, p), math.Mod(theta*p, 2*math.Pi))
+}
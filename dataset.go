@@ -44,6 +44,23 @@ func (d DataSet) Derivative() DataSet {
 	return DataSet(deriv)
 }
 
+// MovingAverage returns the centered moving average over a window of the given
+// width. The window is truncated at the edges of the data set.
+func (d DataSet) MovingAverage(width int) DataSet {
+	avg := make([]float64, len(d))
+	sum := make([]float64, len(d)+1)
+	for i := 0; i < len(d); i++ {
+		sum[i+1] = sum[i] + d[i]
+	}
+	half := width / 2
+	for i := 0; i < len(d); i++ {
+		start := maxInt(0, i-half)
+		stop := minInt(len(d), i-half+width)
+		avg[i] = (sum[stop] - sum[start]) / float64(stop-start)
+	}
+	return avg
+}
+
 // MapRange maps the dataset onto [0, 1) after dividing the value by the entire range.
 func (d DataSet) MapRange() DataSet {
 	min, max := d.Bounds()
@@ -162,6 +179,15 @@ func (d DataSet) Sort() []float64 {
 	return s
 }
 
+// Reverse returns a copy of the data set in reverse order.
+func (d DataSet) Reverse() DataSet {
+	values := make([]float64, len(d))
+	for i := 0; i < len(d); i++ {
+		values[len(d)-1-i] = d[i]
+	}
+	return values
+}
+
 // Median returns the median of the dataset
 func (d DataSet) Median() float64 {
 	s := d.Sort()
@@ -173,6 +199,43 @@ func (d DataSet) Median() float64 {
 	return m
 }
 
+// Percentile returns the p-th percentile (0-100) of the data set using linear
+// interpolation between the closest ranks.
+func (d DataSet) Percentile(p float64) float64 {
+	s := d.Sort()
+	if len(s) == 0 {
+		return math.NaN()
+	}
+	pos := p / 100 * float64(len(s)-1)
+	if pos <= 0 {
+		return s[0]
+	}
+	if pos >= float64(len(s)-1) {
+		return s[len(s)-1]
+	}
+	i := int(pos)
+	frac := pos - float64(i)
+	return s[i] + frac*(s[i+1]-s[i])
+}
+
+// RMS returns the root mean square of the data set
+func (d DataSet) RMS() float64 {
+	if len(d) == 0 {
+		return 0
+	}
+	var ssq float64
+	for i := 0; i < len(d); i++ {
+		ssq += d[i] * d[i]
+	}
+	return math.Sqrt(ssq / float64(len(d)))
+}
+
+// MedianFilter returns the centered running median over a window of k samples.
+// The window is truncated at the edges of the data set.
+func (d DataSet) MedianFilter(k int) DataSet {
+	return medianFilter(d, k)
+}
+
 // MapFunc is a function that can be performed on a dataset
 type MapFunc func(float64) float64
 
@@ -261,3 +324,29 @@ func sumReduce(data []float64) float64 {
 func Scale(value, start1, stop1, start2, stop2 float64) float64 {
 	return start2 + (stop2-start2)*((value-start1)/(stop1-start1))
 }
+
+// Interval is a half-open range [Start, End) of sample indices.
+type Interval struct {
+	Start, End int
+}
+
+// Len returns the number of samples in the interval
+func (r Interval) Len() int {
+	return r.End - r.Start
+}
+
+// minInt returns the smaller of two ints.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of two ints.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,78 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// DCT returns the type II discrete cosine transform of the data set,
+//
+//	y[k] = 2 sum x[n] cos(pi k (2n + 1) / 2N)
+//
+// computed with a single N point FFT.
+func (d DataSet) DCT() DataSet {
+	n := len(d)
+	v := make([]complex128, n)
+	for i := 0; i < (n+1)/2; i++ {
+		v[i] = complex(d[2*i], 0)
+	}
+	for i := 0; i < n/2; i++ {
+		v[n-1-i] = complex(d[2*i+1], 0)
+	}
+	V := FFT(v)
+
+	values := make([]float64, n)
+	for k := range values {
+		w := cmplx.Exp(complex(0, -math.Pi*float64(k)/float64(2*n)))
+		values[k] = 2 * real(w*V[k])
+	}
+	return values
+}
+
+// IDCT returns the inverse of DCT, a scaled type III discrete cosine
+// transform, so that d.DCT().IDCT() reproduces d.
+func (d DataSet) IDCT() DataSet {
+	n := len(d)
+	V := make([]complex128, n)
+	for k := range V {
+		z := complex(d[k], 0)
+		if k > 0 {
+			z -= complex(0, d[n-k])
+		}
+		V[k] = z / 2 * cmplx.Exp(complex(0, math.Pi*float64(k)/float64(2*n)))
+	}
+	v := IFFT(V)
+
+	values := make([]float64, n)
+	for i := 0; i < (n+1)/2; i++ {
+		values[2*i] = real(v[i])
+	}
+	for i := 0; i < n/2; i++ {
+		values[2*i+1] = real(v[n-1-i])
+	}
+	return values
+}
+
+// DST returns the type II discrete sine transform of the data set,
+//
+//	y[k] = 2 sum x[n] sin(pi (k + 1) (2n + 1) / 2N)
+func (d DataSet) DST() DataSet {
+	return alternate(d).DCT().Reverse()
+}
+
+// IDST returns the inverse of DST, so that d.DST().IDST() reproduces d.
+func (d DataSet) IDST() DataSet {
+	return alternate(d.Reverse().IDCT())
+}
+
+// alternate returns x with the sign of every odd sample flipped.
+func alternate(x DataSet) DataSet {
+	values := make([]float64, len(x))
+	for i, v := range x {
+		if i%2 == 1 {
+			v = -v
+		}
+		values[i] = v
+	}
+	return values
+}
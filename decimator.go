@@ -0,0 +1,118 @@
+package dsp
+
+import "math"
+
+// Decimator is a streaming FIR low-pass filter and downsampler. It only
+// evaluates the filter at the samples it keeps, so decimating by D costs 1/D
+// of filtering at the input rate followed by discarding samples. Zero taps,
+// such as every other tap of a half-band filter, are skipped. Taps must not
+// be changed after the decimator is created.
+type Decimator struct {
+	Factor int
+	Taps   DataSet
+
+	nonzero []int
+	history []float64
+	skip    int
+}
+
+// NewDecimator creates a decimator by the given factor with a windowed sinc
+// anti-aliasing filter of the given number of taps, cut off at the output
+// Nyquist frequency.
+func NewDecimator(factor, taps int) *Decimator {
+	return NewDecimatorFIR(factor, sincLowPass(taps, 0.5/float64(factor)))
+}
+
+// NewDecimatorFIR creates a decimator by the given factor with a custom
+// anti-aliasing filter.
+func NewDecimatorFIR(factor int, taps DataSet) *Decimator {
+	return &Decimator{
+		Factor:  factor,
+		Taps:    taps,
+		nonzero: nonzeroTaps(taps, 0, 1),
+		history: make([]float64, len(taps)-1),
+	}
+}
+
+// Process filters and decimates a block of samples, continuing from the state
+// left by the previous block. Blocks need not be a multiple of the factor.
+func (d *Decimator) Process(X []float64) []float64 {
+	h := len(d.history)
+	buf := make([]float64, h+len(X))
+	copy(buf, d.history)
+	copy(buf[h:], X)
+
+	Y := make([]float64, 0, (len(X)+d.Factor-1)/d.Factor)
+	i := d.skip
+	for ; i < len(X); i += d.Factor {
+		// buf[h+i] is the newest sample in the filter
+		var y float64
+		for _, k := range d.nonzero {
+			y += d.Taps[k] * buf[h+i-k]
+		}
+		Y = append(Y, y)
+	}
+	d.skip = i - len(X)
+	copy(d.history, buf[len(buf)-h:])
+	return Y
+}
+
+// RateRatio returns 1/Factor.
+func (d *Decimator) RateRatio() (up, down int) {
+	return 1, d.Factor
+}
+
+// Skip advances the output phase past n dropped input samples.
+func (d *Decimator) Skip(n int) int {
+	skipped := 0
+	if d.skip < n {
+		skipped = (n - d.skip + d.Factor - 1) / d.Factor
+	}
+	d.skip += skipped*d.Factor - n
+	return skipped
+}
+
+// Reset clears the filter history.
+func (d *Decimator) Reset() {
+	for i := range d.history {
+		d.history[i] = 0
+	}
+	d.skip = 0
+}
+
+// nonzeroTaps returns the indices of the nonzero taps start, start+step,
+// start+2*step and so on.
+func nonzeroTaps(taps DataSet, start, step int) []int {
+	var nonzero []int
+	for k := start; k < len(taps); k += step {
+		if taps[k] != 0 {
+			nonzero = append(nonzero, k)
+		}
+	}
+	return nonzero
+}
+
+// sincLowPass designs a linear phase low-pass FIR filter with the given number
+// of taps by windowing an ideal sinc response with a Hamming window. The
+// cutoff is a fraction of the sample rate between 0 and 0.5, and the filter
+// has unit gain at DC.
+func sincLowPass(taps int, cutoff float64) DataSet {
+	w := Symmetric(Hamming, taps)
+	h := make([]float64, taps)
+	center := float64(taps-1) / 2
+	var sum float64
+	for i := range h {
+		t := float64(i) - center
+		if t == 0 {
+			h[i] = 2 * cutoff
+		} else {
+			h[i] = math.Sin(2*math.Pi*cutoff*t) / (math.Pi * t)
+		}
+		h[i] *= w[i]
+		sum += h[i]
+	}
+	for i := range h {
+		h[i] /= sum
+	}
+	return h
+}
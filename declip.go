@@ -0,0 +1,144 @@
+package dsp
+
+import "math"
+
+// DeclipMethod selects how clipped samples are reconstructed.
+type DeclipMethod int
+
+const (
+	// DeclipAR extrapolates into each clipped region with AR models fitted to
+	// the samples before and after it, cross-fading the two predictions.
+	DeclipAR DeclipMethod = iota
+
+	// DeclipSpline interpolates each clipped region with a cubic spline
+	// through the neighbouring unclipped samples.
+	DeclipSpline
+)
+
+// declip context and model sizes
+const (
+	declipOrder   = 16
+	declipContext = 8 * declipOrder
+	declipKnots   = 8
+)
+
+// ClippedRegions returns the runs of samples whose magnitude is at or above
+// the clipping level.
+func (d DataSet) ClippedRegions(level float64) []Interval {
+	var regions []Interval
+	start := -1
+	for i := 0; i < len(d); i++ {
+		clipped := math.Abs(d[i]) >= level
+		if clipped && start < 0 {
+			start = i
+		} else if !clipped && start >= 0 {
+			regions = append(regions, Interval{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		regions = append(regions, Interval{start, len(d)})
+	}
+	return regions
+}
+
+// Declip detects regions clipped at the given level and reconstructs them with
+// the given method. Reconstructed samples never fall below the clipping level,
+// since the true signal was at least that large.
+func (d DataSet) Declip(level float64, method DeclipMethod) DataSet {
+	out := make([]float64, len(d))
+	copy(out, d)
+	for _, r := range d.ClippedRegions(level) {
+		var values []float64
+		if method == DeclipSpline {
+			values = declipSpline(out, r)
+		} else {
+			values = declipAR(out, r)
+		}
+		if values == nil {
+			continue
+		}
+		for i, v := range values {
+			clipped := out[r.Start+i]
+			if math.Abs(v) > math.Abs(clipped) && v*clipped > 0 {
+				out[r.Start+i] = v
+			}
+		}
+	}
+	return out
+}
+
+// declipSpline interpolates the region from the unclipped samples on each side.
+func declipSpline(d DataSet, r Interval) []float64 {
+	var xs, ys []float64
+	for i := r.Start - declipKnots; i < r.Start; i++ {
+		if i >= 0 {
+			xs = append(xs, float64(i))
+			ys = append(ys, d[i])
+		}
+	}
+	for i := r.End; i < r.End+declipKnots && i < len(d); i++ {
+		xs = append(xs, float64(i))
+		ys = append(ys, d[i])
+	}
+	if len(xs) < 2 {
+		return nil
+	}
+	spline := cubicSpline(xs, ys)
+	values := make([]float64, r.Len())
+	for i := range values {
+		values[i] = spline(float64(r.Start + i))
+	}
+	return values
+}
+
+// declipAR predicts the region forwards from the preceding samples and
+// backwards from the following samples, cross-fading the two predictions.
+func declipAR(d DataSet, r Interval) []float64 {
+	before := d[maxInt(0, r.Start-declipContext):r.Start]
+	after := d[r.End:minInt(len(d), r.End+declipContext)]
+
+	var forward, backward []float64
+	if len(before) > 2*declipOrder {
+		forward = arExtrapolate(before, r.Len())
+	}
+	if len(after) > 2*declipOrder {
+		backward = arExtrapolate(after.Reverse(), r.Len())
+		backward = DataSet(backward).Reverse()
+	}
+
+	switch {
+	case forward == nil && backward == nil:
+		return declipSpline(d, r)
+	case backward == nil:
+		return forward
+	case forward == nil:
+		return backward
+	}
+	values := make([]float64, r.Len())
+	for i := range values {
+		w := float64(i+1) / float64(len(values)+1)
+		values[i] = (1-w)*forward[i] + w*backward[i]
+	}
+	return values
+}
+
+// arExtrapolate fits an AR model to the context and predicts n further samples.
+func arExtrapolate(context DataSet, n int) []float64 {
+	model := context.Burg(declipOrder)
+	mean := context.Mean()
+	hist := make([]float64, len(context), len(context)+n)
+	for i := range context {
+		hist[i] = context[i] - mean
+	}
+	values := make([]float64, n)
+	for i := 0; i < n; i++ {
+		var v float64
+		for j := 1; j < len(model.A); j++ {
+			v -= model.A[j] * hist[len(hist)-j]
+		}
+		hist = append(hist, v)
+		values[i] = v + mean
+	}
+	return values
+}
@@ -0,0 +1,46 @@
+package dsp
+
+import "math/cmplx"
+
+// Deconvolve recovers the input of a linear system from its output y and
+// impulse response h by regularized inverse filtering in the frequency domain,
+//
+//	X = conj(H) Y / (|H|^2 + lambda max|H|^2)
+//
+// Where H is small a plain inverse would amplify noise without bound; the
+// Tikhonov term lambda, relative to the peak power of H, caps that gain at
+// the cost of some bias. A lambda of 0 is the exact inverse. The result has
+// the length of y.
+func Deconvolve(y, h DataSet, lambda float64) DataSet {
+	if len(y) == 0 || len(h) == 0 {
+		return DataSet{}
+	}
+	n := NextPow2(len(y) + len(h))
+	Y := realToComplex(y, n)
+	H := realToComplex(h, n)
+	fft(Y, false)
+	fft(H, false)
+
+	var peak float64
+	for _, v := range H {
+		if p := real(v)*real(v) + imag(v)*imag(v); p > peak {
+			peak = p
+		}
+	}
+	reg := lambda * peak
+	for k := range Y {
+		p := real(H[k])*real(H[k]) + imag(H[k])*imag(H[k])
+		if d := p + reg; d > 0 {
+			Y[k] = cmplx.Conj(H[k]) * Y[k] / complex(d, 0)
+		} else {
+			Y[k] = 0
+		}
+	}
+	fft(Y, true)
+
+	values := make([]float64, len(y))
+	for i := range values {
+		values[i] = real(Y[i])
+	}
+	return values
+}
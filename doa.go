@@ -0,0 +1,65 @@
+package dsp
+
+import (
+	"log"
+	"sort"
+)
+
+// Beamscan returns the conventional (delay-and-sum) direction-of-arrival
+// spectrum at frequency f for each azimuth, at a fixed elevation.
+func (a Array) Beamscan(x Frames, f, fS float64, segLen int, azimuths DataSet, el float64) DataSet {
+	return a.SpatialSpectrum(x, f, fS, segLen, azimuths, el, DelayAndSumMethod)
+}
+
+// MUSIC returns the MUSIC pseudo-spectrum at frequency f for each azimuth, at a
+// fixed elevation, assuming the given number of narrowband sources. Peaks are
+// much sharper than the beamscan spectrum, resolving sources closer than the
+// array beamwidth. There must be fewer sources than channels, leaving a noise
+// subspace to project onto.
+func (a Array) MUSIC(x Frames, f, fS float64, segLen, sources int, azimuths DataSet, el float64) DataSet {
+	if sources < 0 || sources >= x.Channels() {
+		log.Fatalf("Array.MUSIC requires between 0 and %d sources, got %d", x.Channels()-1, sources)
+	}
+	R := covariance(Snapshots(x, f, fS, segLen))
+	_, vecs := eigHermitian(R)
+	noise := vecs[sources:]
+
+	spectrum := make([]float64, len(azimuths))
+	for i, az := range azimuths {
+		sv := a.SteeringVector(az, el, f)
+		var proj float64
+		for _, u := range noise {
+			p := cdot(u, sv)
+			proj += real(p)*real(p) + imag(p)*imag(p)
+		}
+		spectrum[i] = 1 / proj
+	}
+	return spectrum
+}
+
+// FindDOA returns the azimuths of the largest peaks in a direction-of-arrival
+// spectrum, strongest first.
+func FindDOA(azimuths, spectrum DataSet, sources int) DataSet {
+	peaks := localMaxima(spectrum)
+	sort.Slice(peaks, func(i, j int) bool { return spectrum[peaks[i]] > spectrum[peaks[j]] })
+	if len(peaks) > sources {
+		peaks = peaks[:sources]
+	}
+	doa := make([]float64, len(peaks))
+	for i, p := range peaks {
+		doa[i] = azimuths[p]
+	}
+	return doa
+}
+
+// localMaxima returns the indices of samples strictly greater than their left
+// neighbour and at least as large as their right neighbour.
+func localMaxima(x []float64) []int {
+	var peaks []int
+	for i := 0; i < len(x); i++ {
+		if (i == 0 || x[i] > x[i-1]) && (i == len(x)-1 || x[i] >= x[i+1]) {
+			peaks = append(peaks, i)
+		}
+	}
+	return peaks
+}
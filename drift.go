@@ -0,0 +1,144 @@
+package dsp
+
+import "math"
+
+// driftHalfWidth is the number of input samples on each side of an output
+// used by the DriftCorrector interpolation kernel.
+const driftHalfWidth = 8
+
+// ClockDrift is the relative sample clock error between two recordings of
+// the same signal, modelled as a lag that grows linearly with time, so that
+// a[n+Offset+Rate*n] matches b[n].
+type ClockDrift struct {
+	// Offset is the lag of a behind b at the first sample, in samples.
+	Offset float64
+
+	// Rate is the growth of the lag per sample of b, the fractional amount
+	// by which the clock of a runs fast.
+	Rate float64
+
+	// Positions and Lags hold the center of each segment in b and the lag
+	// measured there.
+	Positions DataSet
+	Lags      DataSet
+}
+
+// PPM returns the drift rate in parts per million.
+func (c *ClockDrift) PPM() float64 {
+	return c.Rate * 1e6
+}
+
+// EstimateDrift measures the clock drift of a relative to b by correlating
+// segments of segmentLen samples every hop samples and fitting a line to the
+// lags. Each segment of a is taken around the lag measured for the one
+// before, so the lag can grow far beyond maxLag, which only bounds the
+// change between segments. The signals should share broadband content, such
+// as speech or noise, for the correlation peaks to be sharp.
+func EstimateDrift(a, b DataSet, segmentLen, hop, maxLag int) *ClockDrift {
+	c := &ClockDrift{}
+	var shift int
+	for p := 0; p+segmentLen <= len(b); p += hop {
+		start := p + shift
+		if start < 0 || start+segmentLen > len(a) {
+			break
+		}
+		lag, _ := CorrelationLag(a[start:start+segmentLen], b[p:p+segmentLen], maxLag)
+		lag += float64(shift)
+		c.Positions = append(c.Positions, float64(p)+float64(segmentLen-1)/2)
+		c.Lags = append(c.Lags, lag)
+		shift = int(math.Round(lag))
+	}
+
+	n := float64(len(c.Lags))
+	if n == 0 {
+		return c
+	}
+	if n == 1 {
+		c.Offset = c.Lags[0]
+		return c
+	}
+
+	// least squares line through the lags
+	var st, sl, stt, stl float64
+	for i, l := range c.Lags {
+		t := c.Positions[i]
+		st += t
+		sl += l
+		stt += t * t
+		stl += t * l
+	}
+	c.Rate = (n*stl - st*sl) / (n*stt - st*st)
+	c.Offset = (sl - c.Rate*st) / n
+	return c
+}
+
+// DriftCorrector is a streaming Processor that resamples a stream onto the
+// clock of another, removing a measured ClockDrift so the two can be fused
+// sample for sample. Each output is interpolated from the input with a
+// Blackman windowed sinc, and is only produced once the driftHalfWidth input
+// samples after it have arrived. Blocks produce about
+// len(X)/(1+Rate) outputs, varying by one as the drift accumulates.
+type DriftCorrector struct {
+	// Offset and Rate place output n at input position Offset+n*(1+Rate).
+	// Rate can be updated as the drift is tracked.
+	Offset float64
+	Rate   float64
+
+	buf []float64
+	pos float64
+}
+
+// NewDriftCorrector creates a corrector removing the given drift.
+func NewDriftCorrector(drift *ClockDrift) *DriftCorrector {
+	c := &DriftCorrector{Offset: drift.Offset, Rate: drift.Rate}
+	c.Reset()
+	return c
+}
+
+// Process resamples a block of samples, continuing from the state left by
+// the previous block.
+func (c *DriftCorrector) Process(X []float64) []float64 {
+	c.buf = append(c.buf, X...)
+	var Y []float64
+	for c.pos+driftHalfWidth < float64(len(c.buf)) {
+		Y = append(Y, c.interpolate(c.pos))
+		c.pos += 1 + c.Rate
+	}
+
+	// drop the samples no later output can reach
+	if drop := int(math.Floor(c.pos)) - driftHalfWidth; drop > 0 {
+		drop = minInt(drop, len(c.buf))
+		c.buf = append(c.buf[:0], c.buf[drop:]...)
+		c.pos -= float64(drop)
+	}
+	if Y == nil {
+		Y = []float64{}
+	}
+	return Y
+}
+
+// Reset clears the buffered input and restarts from Offset.
+func (c *DriftCorrector) Reset() {
+	c.buf = make([]float64, driftHalfWidth)
+	c.pos = c.Offset + driftHalfWidth
+}
+
+// interpolate evaluates the buffered input at a fractional position.
+// Samples outside the buffer read as zero.
+func (c *DriftCorrector) interpolate(t float64) float64 {
+	i0 := int(math.Floor(t))
+	var y float64
+	for i := i0 - driftHalfWidth + 1; i <= i0+driftHalfWidth; i++ {
+		if i < 0 || i >= len(c.buf) {
+			continue
+		}
+		x := t - float64(i)
+		w := 0.42 + 0.5*math.Cos(math.Pi*x/driftHalfWidth) + 0.08*math.Cos(2*math.Pi*x/driftHalfWidth)
+		s := 1.0
+		if x != 0 {
+			s = math.Sin(math.Pi*x) / (math.Pi * x)
+		}
+		y += c.buf[i] * s * w
+	}
+	return y
+}
@@ -0,0 +1,150 @@
+package dsp
+
+import "math"
+
+// Pan-Tompkins timing constants in seconds
+const (
+	qrsIntegration = 0.150
+	qrsRefractory  = 0.200
+	qrsSearch      = 0.075
+)
+
+// HRV holds basic time-domain heart rate variability metrics computed from RR
+// intervals in seconds.
+type HRV struct {
+	// MeanRR is the mean RR interval in seconds.
+	MeanRR float64
+
+	// MeanHR is the mean heart rate in beats per minute.
+	MeanHR float64
+
+	// SDNN is the standard deviation of the RR intervals in seconds.
+	SDNN float64
+
+	// RMSSD is the root mean square of successive RR differences in seconds.
+	RMSSD float64
+}
+
+// RPeaks detects the R peaks of an ECG with the Pan-Tompkins algorithm:
+// 5-15 Hz band-pass filtering, differentiation, squaring and moving window
+// integration, followed by adaptive signal and noise thresholds with a
+// refractory period and search-back for missed beats. It returns the sample
+// index of each R peak.
+func (d DataSet) RPeaks(fS float64) []int {
+	hp := NewHighPassFilter(5, fS)
+	lp := NewLowPassFilter(15, fS)
+	band := DataSet(lp.FiltFilt(hp.FiltFilt(d)))
+
+	// five point derivative, squared
+	energy := make(DataSet, len(band))
+	for i := 2; i < len(band)-2; i++ {
+		v := (-band[i-2] - 2*band[i-1] + 2*band[i+1] + band[i+2]) / 8
+		energy[i] = v * v
+	}
+	integrated := energy.MovingAverage(int(qrsIntegration * fS))
+
+	refractory := int(qrsRefractory * fS)
+	candidates := localMaxima(integrated)
+
+	// initialize the thresholds from the first two seconds
+	learn := integrated[:minInt(len(integrated), int(2*fS))]
+	spki := 0.25 * learn.Max()
+	npki := 0.5 * learn.Mean()
+
+	var beats []int
+	var rrAvg float64
+	lastBeat := -refractory
+	for ci, c := range candidates {
+		peak := integrated[c]
+		threshold := npki + 0.25*(spki-npki)
+		if c-lastBeat < refractory {
+			continue
+		}
+
+		// search back for a missed beat with half the threshold
+		if rrAvg > 0 && float64(c-lastBeat) > 1.66*rrAvg {
+			best := -1
+			for _, p := range candidates[:ci] {
+				if p-lastBeat >= refractory && c-p >= refractory && integrated[p] > threshold/2 &&
+					(best < 0 || integrated[p] > integrated[best]) {
+					best = p
+				}
+			}
+			if best >= 0 {
+				spki = 0.25*integrated[best] + 0.75*spki
+				beats, rrAvg = appendBeat(beats, best, lastBeat, rrAvg)
+				lastBeat = best
+				threshold = npki + 0.25*(spki-npki)
+			}
+		}
+
+		if peak > threshold {
+			spki = 0.125*peak + 0.875*spki
+			beats, rrAvg = appendBeat(beats, c, lastBeat, rrAvg)
+			lastBeat = c
+		} else {
+			npki = 0.125*peak + 0.875*npki
+		}
+	}
+
+	// locate the R peak in the filtered ECG around each detection
+	search := int(qrsSearch * fS)
+	peaks := make([]int, 0, len(beats))
+	for _, b := range beats {
+		best := b
+		for i := maxInt(0, b-search); i < minInt(len(band), b+search); i++ {
+			if band[i] > band[best] {
+				best = i
+			}
+		}
+		if len(peaks) == 0 || best != peaks[len(peaks)-1] {
+			peaks = append(peaks, best)
+		}
+	}
+	return peaks
+}
+
+// appendBeat records a detected beat and updates the running RR average.
+func appendBeat(beats []int, beat, lastBeat int, rrAvg float64) ([]int, float64) {
+	if len(beats) > 0 {
+		rr := float64(beat - lastBeat)
+		if rrAvg == 0 {
+			rrAvg = rr
+		} else {
+			rrAvg = 0.125*rr + 0.875*rrAvg
+		}
+	}
+	return append(beats, beat), rrAvg
+}
+
+// RRIntervals returns the intervals in seconds between successive R peaks.
+func RRIntervals(peaks []int, fS float64) DataSet {
+	if len(peaks) < 2 {
+		return DataSet{}
+	}
+	rr := make([]float64, len(peaks)-1)
+	for i := range rr {
+		rr[i] = float64(peaks[i+1]-peaks[i]) / fS
+	}
+	return rr
+}
+
+// NewHRV computes the heart rate variability metrics of the RR intervals.
+func NewHRV(rr DataSet) HRV {
+	var h HRV
+	if len(rr) == 0 {
+		return h
+	}
+	h.MeanRR = rr.Mean()
+	h.MeanHR = 60 / h.MeanRR
+	h.SDNN = rr.Stdev()
+	if len(rr) > 1 {
+		var ssq float64
+		for i := 1; i < len(rr); i++ {
+			diff := rr[i] - rr[i-1]
+			ssq += diff * diff
+		}
+		h.RMSSD = math.Sqrt(ssq / float64(len(rr)-1))
+	}
+	return h
+}
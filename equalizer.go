@@ -0,0 +1,148 @@
+package dsp
+
+import "log"
+
+// equalizerRamp is the number of samples over which an equalizer band glides
+// to new settings, avoiding zipper noise.
+const equalizerRamp = 256
+
+// BandType selects the filter shape of an equalizer band.
+type BandType int
+
+const (
+	// PeakingBand boosts or cuts a band around its frequency.
+	PeakingBand BandType = iota
+
+	// LowShelfBand boosts or cuts everything below its frequency.
+	LowShelfBand
+
+	// HighShelfBand boosts or cuts everything above its frequency.
+	HighShelfBand
+)
+
+// EQBand is the setting of one equalizer band.
+type EQBand struct {
+	Type BandType
+
+	// Freq is the center or corner frequency in Hz.
+	Freq float64
+
+	// Gain is the boost or cut in dB.
+	Gain float64
+
+	// Q is the quality factor of a peaking band or the slope of a shelf.
+	Q float64
+
+	// Enabled switches the band in or out.
+	Enabled bool
+}
+
+// filter designs the biquad for the band, or a unity filter if it is
+// disabled.
+func (b EQBand) filter(fS float64) *Filter {
+	if !b.Enabled {
+		return &Filter{B: []float64{1, 0, 0}, A: []float64{1, 0, 0}}
+	}
+	switch b.Type {
+	case LowShelfBand:
+		return NewLowShelfFilter(b.Freq, b.Gain, b.Q, fS)
+	case HighShelfBand:
+		return NewHighShelfFilter(b.Freq, b.Gain, b.Q, fS)
+	}
+	return NewPeakingEQ(b.Freq, b.Q, b.Gain, fS)
+}
+
+// Equalizer is a multi-band parametric equalizer built from a cascade of
+// peaking and shelf biquads. Bands can be changed while audio is running;
+// each glides to its new setting over a few milliseconds.
+type Equalizer struct {
+	SampleRate float64
+
+	bands   []EQBand
+	filters []*StreamFilter
+}
+
+// NewEqualizer creates an equalizer with the given bands.
+func NewEqualizer(fS float64, bands ...EQBand) *Equalizer {
+	e := &Equalizer{SampleRate: fS}
+	for _, b := range bands {
+		e.AddBand(b)
+	}
+	return e
+}
+
+// AddBand appends a band and returns its index.
+func (e *Equalizer) AddBand(b EQBand) int {
+	e.bands = append(e.bands, b)
+	e.filters = append(e.filters, NewStreamFilter(b.filter(e.SampleRate)))
+	return len(e.bands) - 1
+}
+
+// Len returns the number of bands.
+func (e *Equalizer) Len() int {
+	return len(e.bands)
+}
+
+// Band returns the setting of band i.
+func (e *Equalizer) Band(i int) EQBand {
+	e.check(i)
+	return e.bands[i]
+}
+
+// SetBand changes the setting of band i.
+func (e *Equalizer) SetBand(i int, b EQBand) {
+	e.check(i)
+	e.bands[i] = b
+	e.filters[i].Ramp(b.filter(e.SampleRate), equalizerRamp)
+}
+
+// SetGain changes the gain of band i in dB.
+func (e *Equalizer) SetGain(i int, gain float64) {
+	b := e.Band(i)
+	b.Gain = gain
+	e.SetBand(i, b)
+}
+
+// SetEnabled switches band i in or out.
+func (e *Equalizer) SetEnabled(i int, enabled bool) {
+	b := e.Band(i)
+	b.Enabled = enabled
+	e.SetBand(i, b)
+}
+
+// Response returns the combined complex frequency response of the enabled
+// bands at their target settings.
+func (e *Equalizer) Response(freq float64) complex128 {
+	h := complex(1, 0)
+	for _, b := range e.bands {
+		h *= b.filter(e.SampleRate).Response(freq, e.SampleRate)
+	}
+	return h
+}
+
+// Process equalizes a block of samples.
+func (e *Equalizer) Process(X []float64) []float64 {
+	Y := X
+	for _, f := range e.filters {
+		Y = f.Process(Y)
+	}
+	if len(e.filters) == 0 {
+		Y = make([]float64, len(X))
+		copy(Y, X)
+	}
+	return Y
+}
+
+// Reset clears the filter state of every band.
+func (e *Equalizer) Reset() {
+	for _, f := range e.filters {
+		f.Reset()
+	}
+}
+
+// check stops the program if i is not a band index.
+func (e *Equalizer) check(i int) {
+	if i < 0 || i >= len(e.bands) {
+		log.Fatalf("Equalizer has %d bands, got band %d", len(e.bands), i)
+	}
+}
@@ -0,0 +1,129 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// PSK returns the m points of a phase shift keying constellation on the unit
+// circle, starting at phase zero.
+func PSK(m int) IQ {
+	points := make([]complex128, m)
+	for i := range points {
+		points[i] = cmplx.Rect(1, 2*math.Pi*float64(i)/float64(m))
+	}
+	return points
+}
+
+// QAM returns the m points of a square quadrature amplitude modulation
+// constellation, scaled to unit average power. m must be a square such as 4,
+// 16 or 64.
+func QAM(m int) IQ {
+	side := int(math.Round(math.Sqrt(float64(m))))
+	if side*side != m {
+		log.Fatalf("QAM requires a square number of points, got %d", m)
+	}
+	points := make([]complex128, 0, m)
+	var power float64
+	for i := 0; i < side; i++ {
+		for q := 0; q < side; q++ {
+			p := complex(float64(2*i-side+1), float64(2*q-side+1))
+			points = append(points, p)
+			power += real(p)*real(p) + imag(p)*imag(p)
+		}
+	}
+	scale := complex(1/math.Sqrt(power/float64(m)), 0)
+	for i := range points {
+		points[i] *= scale
+	}
+	return points
+}
+
+// Decide returns the index of the nearest constellation point to each
+// received point.
+func Decide(received, constellation IQ) []int {
+	decisions := make([]int, len(received))
+	for i, r := range received {
+		best := math.Inf(1)
+		for j, c := range constellation {
+			if dist := cmplx.Abs(r - c); dist < best {
+				best = dist
+				decisions[i] = j
+			}
+		}
+	}
+	return decisions
+}
+
+// EVM returns the RMS error vector magnitude of the received points against
+// the nearest points of the ideal constellation, relative to the RMS
+// magnitude of the constellation. Multiply by 100 for percent. Decisions are
+// made blind, so points pushed past a decision boundary count towards the
+// wrong symbol and the EVM reads low; use EVMReference when the transmitted
+// symbols are known.
+func EVM(received, constellation IQ) float64 {
+	ideal := make([]complex128, len(received))
+	for i, j := range Decide(received, constellation) {
+		ideal[i] = constellation[j]
+	}
+	return evm(received, ideal, rmsPower(constellation))
+}
+
+// EVMReference returns the RMS error vector magnitude of the received points
+// against the known transmitted points, relative to their RMS magnitude.
+func EVMReference(received, reference IQ) float64 {
+	if len(received) != len(reference) {
+		log.Fatalf("EVMReference requires signals of equal length, got %d and %d", len(received), len(reference))
+	}
+	return evm(received, reference, rmsPower(reference))
+}
+
+// evm returns the RMS error between received and ideal relative to the
+// square root of the reference power.
+func evm(received, ideal IQ, power float64) float64 {
+	if len(received) == 0 || power == 0 {
+		return 0
+	}
+	var sum float64
+	for i, r := range received {
+		e := r - ideal[i]
+		sum += real(e)*real(e) + imag(e)*imag(e)
+	}
+	return math.Sqrt(sum / float64(len(received)) / power)
+}
+
+// rmsPower returns the mean power of the points.
+func rmsPower(points IQ) float64 {
+	if len(points) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, p := range points {
+		sum += real(p)*real(p) + imag(p)*imag(p)
+	}
+	return sum / float64(len(points))
+}
+
+// BitErrors returns the number of bits that differ between two bit streams
+// packed eight bits to a byte.
+func BitErrors(received, reference []byte) int {
+	if len(received) != len(reference) {
+		log.Fatalf("BitErrors requires streams of equal length, got %d and %d", len(received), len(reference))
+	}
+	errors := 0
+	for i := range received {
+		errors += bits.OnesCount8(received[i] ^ reference[i])
+	}
+	return errors
+}
+
+// BER returns the bit error rate between two bit streams packed eight bits to
+// a byte.
+func BER(received, reference []byte) float64 {
+	if len(received) == 0 {
+		return 0
+	}
+	return float64(BitErrors(received, reference)) / float64(8*len(received))
+}
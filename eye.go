@@ -0,0 +1,50 @@
+package dsp
+
+import "math"
+
+// EyeDiagram holds the traces of an eye diagram, ready to be drawn on top of
+// each other.
+type EyeDiagram struct {
+	// Time is the position of each trace point in symbol periods, from 0 to
+	// the span of the traces.
+	Time DataSet
+
+	// Traces holds one run of the signal per symbol period.
+	Traces []DataSet
+}
+
+// EyeDiagram folds the data set modulo the symbol period into traces that
+// each span the given number of symbols, usually 2. A new trace starts every
+// symbol, the first at offset samples, so successive traces overlap. The
+// symbol period may be fractional; traces are resampled by linear
+// interpolation onto a grid with one point per sample period, so they line up
+// however the symbol clock falls between samples.
+func (d DataSet) EyeDiagram(samplesPerSymbol, offset float64, span int) *EyeDiagram {
+	points := int(math.Ceil(samplesPerSymbol))*span + 1
+	eye := &EyeDiagram{Time: make(DataSet, points)}
+	for j := range eye.Time {
+		eye.Time[j] = float64(span) * float64(j) / float64(points-1)
+	}
+
+	length := samplesPerSymbol * float64(span)
+	for start := offset; start+length <= float64(len(d)-1); start += samplesPerSymbol {
+		trace := make([]float64, points)
+		for j, t := range eye.Time {
+			trace[j] = sampleAt(d, start+t*samplesPerSymbol)
+		}
+		eye.Traces = append(eye.Traces, trace)
+	}
+	return eye
+}
+
+// Constellation samples the signal once per symbol, starting at offset
+// samples, to give the constellation points. The symbol period may be
+// fractional; samples between points are linearly interpolated.
+func (s IQ) Constellation(samplesPerSymbol, offset float64) IQ {
+	I, Q := s.I(), s.Q()
+	var points []complex128
+	for t := offset; t <= float64(len(s)-1); t += samplesPerSymbol {
+		points = append(points, complex(sampleAt(I, t), sampleAt(Q, t)))
+	}
+	return points
+}
@@ -0,0 +1,198 @@
+package dsp
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/eliquious/dsp/arrow"
+)
+
+// Feature selects a group of per-frame features for a FeatureExtractor.
+type Feature int
+
+const (
+	// MFCCFeature is the mel frequency cepstral coefficients.
+	MFCCFeature Feature = iota
+
+	// SpectralFeature is the spectral centroid, spread, rolloff, flux,
+	// flatness and crest.
+	SpectralFeature
+
+	// ZCRFeature is the zero crossing rate.
+	ZCRFeature
+
+	// EnergyFeature is the frame energy.
+	EnergyFeature
+)
+
+// ZeroCrossingRate returns the fraction of successive samples that change
+// sign, a cheap measure of noisiness or dominant frequency.
+func (d DataSet) ZeroCrossingRate() float64 {
+	if len(d) < 2 {
+		return 0
+	}
+	crossings := 0
+	for i := 1; i < len(d); i++ {
+		if (d[i-1] < 0) != (d[i] < 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / float64(len(d)-1)
+}
+
+// Energy returns the sum of the squared samples.
+func (d DataSet) Energy() float64 {
+	var sum float64
+	for _, v := range d {
+		sum += v * v
+	}
+	return sum
+}
+
+// FeatureExtractor computes a matrix of features, one row per frame, for
+// training or running machine learning models.
+type FeatureExtractor struct {
+	// WindowSize and Hop set the analysis frames, which are centered on
+	// every Hop samples as for STFT.
+	WindowSize int
+	Hop        int
+
+	// SampleRate is the sample rate of the data in Hz.
+	SampleRate float64
+
+	// MFCCs is the number of cepstral coefficients for MFCCFeature.
+	MFCCs int
+
+	// Rolloff is the fraction used for the spectral rolloff.
+	Rolloff float64
+
+	// Features lists the feature groups, in column order.
+	Features []Feature
+}
+
+// NewFeatureExtractor creates an extractor for the given feature groups with
+// 13 MFCCs and the default rolloff.
+func NewFeatureExtractor(windowSize, hop int, fS float64, features ...Feature) *FeatureExtractor {
+	return &FeatureExtractor{
+		WindowSize: windowSize,
+		Hop:        hop,
+		SampleRate: fS,
+		MFCCs:      13,
+		Rolloff:    DefaultRolloff,
+		Features:   features,
+	}
+}
+
+// Names returns the name of each column of the feature matrix.
+func (e *FeatureExtractor) Names() []string {
+	var names []string
+	for _, f := range e.Features {
+		switch f {
+		case MFCCFeature:
+			for i := 0; i < e.MFCCs; i++ {
+				names = append(names, fmt.Sprintf("mfcc%d", i))
+			}
+		case SpectralFeature:
+			names = append(names, "centroid", "spread", "rolloff", "flux", "flatness", "crest")
+		case ZCRFeature:
+			names = append(names, "zcr")
+		case EnergyFeature:
+			names = append(names, "energy")
+		}
+	}
+	return names
+}
+
+// FeatureMatrix holds per-frame features with one row per frame.
+type FeatureMatrix struct {
+	Names []string
+	Times DataSet
+	Data  [][]float64
+}
+
+// Extract computes the feature matrix of the data set.
+func (e *FeatureExtractor) Extract(d DataSet) *FeatureMatrix {
+	s := d.STFT(e.WindowSize, e.Hop, nil)
+	m := &FeatureMatrix{
+		Names: e.Names(),
+		Times: s.Times(e.SampleRate),
+		Data:  make([][]float64, len(s.Data)),
+	}
+
+	var mfccs [][]float64
+	var spectral []SpectralFeatures
+	for _, f := range e.Features {
+		switch f {
+		case MFCCFeature:
+			mfccs = s.MFCC(e.SampleRate, e.MFCCs)
+		case SpectralFeature:
+			spectral = s.Features(e.SampleRate, e.Rolloff)
+		}
+	}
+
+	frame := make(DataSet, e.WindowSize)
+	for t := range m.Data {
+		// the samples of the frame, zero beyond the ends as for STFT
+		for i := range frame {
+			frame[i] = 0
+			if j := t*e.Hop - e.WindowSize/2 + i; j >= 0 && j < len(d) {
+				frame[i] = d[j]
+			}
+		}
+
+		row := make([]float64, 0, len(m.Names))
+		for _, f := range e.Features {
+			switch f {
+			case MFCCFeature:
+				row = append(row, mfccs[t]...)
+			case SpectralFeature:
+				sf := spectral[t]
+				row = append(row, sf.Centroid, sf.Spread, sf.Rolloff, sf.Flux, sf.Flatness, sf.Crest)
+			case ZCRFeature:
+				row = append(row, frame.ZeroCrossingRate())
+			case EnergyFeature:
+				row = append(row, frame.Energy())
+			}
+		}
+		m.Data[t] = row
+	}
+	return m
+}
+
+// WriteCSV writes the feature matrix as CSV with a header row, a time column
+// in seconds and one column per feature.
+func (m *FeatureMatrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"time"}, m.Names...)); err != nil {
+		return err
+	}
+	record := make([]string, len(m.Names)+1)
+	for t, row := range m.Data {
+		record[0] = strconv.FormatFloat(m.Times[t], 'g', -1, 64)
+		for j, v := range row {
+			record[j+1] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteArrow writes the feature matrix in the Arrow IPC file format, with a
+// float64 time column in seconds followed by one column per feature, for
+// loading into pyarrow, pandas or polars without parsing text.
+func (m *FeatureMatrix) WriteArrow(w io.Writer) error {
+	columns := make([][]float64, len(m.Names)+1)
+	columns[0] = m.Times
+	for j := range m.Names {
+		columns[j+1] = make([]float64, len(m.Data))
+		for t, row := range m.Data {
+			columns[j+1][t] = row[j]
+		}
+	}
+	return arrow.WriteFile(w, append([]string{"time"}, m.Names...), columns)
+}
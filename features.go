@@ -0,0 +1,150 @@
+package dsp
+
+import "math"
+
+// DefaultRolloff is the fraction of spectral magnitude below the rolloff
+// frequency commonly used for audio features.
+const DefaultRolloff = 0.85
+
+// flatnessFloor keeps the geometric mean finite for spectra containing zeros.
+const flatnessFloor = 1e-20
+
+// SpectralFeatures summarizes the shape of a magnitude spectrum.
+type SpectralFeatures struct {
+	// Centroid is the magnitude weighted mean frequency.
+	Centroid float64
+
+	// Spread is the magnitude weighted standard deviation of frequency
+	// about the centroid.
+	Spread float64
+
+	// Rolloff is the frequency below which the given fraction of the total
+	// magnitude lies.
+	Rolloff float64
+
+	// Flux is the Euclidean distance to the previous spectrum.
+	Flux float64
+
+	// Flatness is the ratio of the geometric to the arithmetic mean of the
+	// power spectrum, near 1 for noise and near 0 for tones.
+	Flatness float64
+
+	// Crest is the ratio of the largest magnitude to the mean magnitude.
+	Crest float64
+}
+
+// NewSpectralFeatures computes the features of the magnitude spectrum mag
+// with bin frequencies freqs. The flux is measured against prev, the previous
+// spectrum, and is zero if prev is nil.
+func NewSpectralFeatures(mag, freqs, prev DataSet, rolloff float64) SpectralFeatures {
+	return SpectralFeatures{
+		Centroid: SpectralCentroid(mag, freqs),
+		Spread:   SpectralSpread(mag, freqs),
+		Rolloff:  SpectralRolloff(mag, freqs, rolloff),
+		Flux:     SpectralFlux(prev, mag),
+		Flatness: SpectralFlatness(mag),
+		Crest:    SpectralCrest(mag),
+	}
+}
+
+// Features computes the spectral features of every frame of the spectrogram.
+func (s *Spectrogram) Features(fS, rolloff float64) []SpectralFeatures {
+	freqs := s.Frequencies(fS)
+	features := make([]SpectralFeatures, len(s.Data))
+	var prev DataSet
+	for t, mag := range s.Magnitude() {
+		features[t] = NewSpectralFeatures(mag, freqs, prev, rolloff)
+		prev = mag
+	}
+	return features
+}
+
+// SpectralCentroid returns the magnitude weighted mean frequency.
+func SpectralCentroid(mag, freqs DataSet) float64 {
+	var sum, weighted float64
+	for k, m := range mag {
+		sum += m
+		weighted += m * freqs[k]
+	}
+	if sum == 0 {
+		return 0
+	}
+	return weighted / sum
+}
+
+// SpectralSpread returns the magnitude weighted standard deviation of
+// frequency about the centroid.
+func SpectralSpread(mag, freqs DataSet) float64 {
+	c := SpectralCentroid(mag, freqs)
+	var sum, weighted float64
+	for k, m := range mag {
+		d := freqs[k] - c
+		sum += m
+		weighted += m * d * d
+	}
+	if sum == 0 {
+		return 0
+	}
+	return math.Sqrt(weighted / sum)
+}
+
+// SpectralRolloff returns the lowest frequency below which the fraction of
+// the total magnitude lies.
+func SpectralRolloff(mag, freqs DataSet, fraction float64) float64 {
+	threshold := fraction * mag.Sum()
+	var sum float64
+	for k, m := range mag {
+		sum += m
+		if sum >= threshold {
+			return freqs[k]
+		}
+	}
+	if len(freqs) == 0 {
+		return 0
+	}
+	return freqs[len(freqs)-1]
+}
+
+// SpectralFlux returns the Euclidean distance between successive magnitude
+// spectra, or zero if prev is nil.
+func SpectralFlux(prev, mag DataSet) float64 {
+	if prev == nil {
+		return 0
+	}
+	checkLengths("SpectralFlux", prev, mag)
+	var sum float64
+	for k, m := range mag {
+		d := m - prev[k]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// SpectralFlatness returns the ratio of the geometric to the arithmetic mean
+// of the power spectrum.
+func SpectralFlatness(mag DataSet) float64 {
+	if len(mag) == 0 {
+		return 0
+	}
+	var logSum, sum float64
+	for _, m := range mag {
+		p := math.Max(m*m, flatnessFloor)
+		logSum += math.Log(p)
+		sum += p
+	}
+	n := float64(len(mag))
+	return math.Exp(logSum/n) / (sum / n)
+}
+
+// SpectralCrest returns the ratio of the largest magnitude to the mean
+// magnitude.
+func SpectralCrest(mag DataSet) float64 {
+	if len(mag) == 0 {
+		return 0
+	}
+	mean := mag.Mean()
+	if mean == 0 {
+		return 0
+	}
+	return mag.Max() / mean
+}
@@ -0,0 +1,303 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// FFT returns the discrete Fourier transform of x. Power of two lengths use a
+// radix-2 FFT; other lengths use Bluestein's algorithm, which is also
+// O(N log N) but several times slower. Use PadToPow2 to trade exact bin
+// frequencies for speed.
+func FFT(x []complex128) []complex128 {
+	return backend.FFT(x, false)
+}
+
+// FFT returns the discrete Fourier transform of the data set as complex bins.
+func (d DataSet) FFT() []complex128 {
+	return FFT(realToComplex(d, len(d)))
+}
+
+// RFFT returns the non-negative frequency bins 0 through N/2 of the discrete
+// Fourier transform of the data set. The remaining bins of a real signal are
+// their complex conjugates.
+func (d DataSet) RFFT() []complex128 {
+	if len(d) == 0 {
+		return []complex128{}
+	}
+	return d.FFT()[:len(d)/2+1]
+}
+
+// IFFT returns the inverse discrete Fourier transform of the bins, scaled by
+// 1/N so that IFFT(FFT(x)) reproduces x.
+func IFFT(bins []complex128) []complex128 {
+	return backend.FFT(bins, true)
+}
+
+// IFFTReal returns the real part of the inverse transform of a full set of
+// bins, reconstructing the time domain data set of a real signal.
+func IFFTReal(bins []complex128) DataSet {
+	x := IFFT(bins)
+	values := make([]float64, len(x))
+	for i, v := range x {
+		values[i] = real(v)
+	}
+	return values
+}
+
+// IRFFT reconstructs a real signal of length n from the non-negative frequency
+// bins returned by RFFT, filling in the negative frequencies by conjugate
+// symmetry.
+func IRFFT(bins []complex128, n int) DataSet {
+	return irfft(bins, n)
+}
+
+// FilterFFT applies a real frequency domain gain mask to the signal. The mask
+// gives the gain from 0 Hz to the Nyquist frequency; if it does not have
+// exactly N/2+1 points for a signal of length N it is linearly interpolated
+// onto the bins. Negative frequencies receive the same gain so the output
+// stays real. The filtering is circular, so zero pad the signal if its ends
+// must not wrap into each other.
+func FilterFFT(signal, mask DataSet) DataSet {
+	n := len(signal)
+	if n == 0 || len(mask) == 0 {
+		return DataSet{}
+	}
+	bins := signal.RFFT()
+	for k := range bins {
+		bins[k] *= complex(maskGain(mask, k, len(bins)), 0)
+	}
+	return irfft(bins, n)
+}
+
+// FFTFreqs returns the frequency of each bin of an n point FFT, with the bins
+// above n/2 mapped to negative frequencies.
+func FFTFreqs(n int, fS float64) DataSet {
+	freqs := make([]float64, n)
+	for k := range freqs {
+		freqs[k] = float64(k) * fS / float64(n)
+		if k > (n-1)/2 {
+			freqs[k] -= fS
+		}
+	}
+	return freqs
+}
+
+// Magnitude returns the magnitude of each complex bin.
+func Magnitude(bins []complex128) DataSet {
+	mags := make([]float64, len(bins))
+	for i, v := range bins {
+		mags[i] = cmplx.Abs(v)
+	}
+	return mags
+}
+
+// Phase returns the phase of each complex bin in radians.
+func Phase(bins []complex128) DataSet {
+	phase := make([]float64, len(bins))
+	for i, v := range bins {
+		phase[i] = cmplx.Phase(v)
+	}
+	return phase
+}
+
+// PadToPow2 returns the data set zero padded to the next power of two length.
+func (d DataSet) PadToPow2() DataSet {
+	values := make([]float64, NextPow2(len(d)))
+	copy(values, d)
+	return values
+}
+
+// isPow2 returns true if n is a positive power of two.
+func isPow2(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// dftDirectLimit is the length up to which cpuDFT sums directly rather than
+// using Bluestein's algorithm.
+const dftDirectLimit = 16
+
+// fft computes the discrete Fourier transform of x in place with the current
+// backend, scaling the inverse transform by 1/N. The CPU backend requires a
+// power of two length and transforms without copying.
+func fft(x []complex128, inverse bool) {
+	if len(x) <= 1 {
+		return
+	}
+	if !offloaded() {
+		radix2FFT(x, inverse)
+		return
+	}
+	copy(x, backend.FFT(x, inverse))
+}
+
+// dft returns the discrete Fourier transform of x for any length, computed
+// by the current backend.
+func dft(x []complex128, inverse bool) []complex128 {
+	if !offloaded() {
+		return cpuDFT(x, inverse)
+	}
+	return backend.FFT(x, inverse)
+}
+
+// cpuDFT computes the discrete Fourier transform of x for any length,
+// directly for short inputs and with Bluestein's algorithm otherwise.
+func cpuDFT(x []complex128, inverse bool) []complex128 {
+	n := len(x)
+	if n > dftDirectLimit {
+		return bluestein(x, inverse)
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	X := make([]complex128, n)
+	for k := 0; k < n; k++ {
+		var sum complex128
+		for i := 0; i < n; i++ {
+			sum += x[i] * cmplx.Rect(1, sign*2*math.Pi*float64((k*i)%n)/float64(n))
+		}
+		X[k] = sum
+	}
+	if inverse {
+		for k := range X {
+			X[k] /= complex(float64(n), 0)
+		}
+	}
+	return X
+}
+
+// NextPow2 returns the smallest power of two greater than or equal to n.
+func NextPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// bluestein computes the discrete Fourier transform of x for any length by
+// rewriting it as a convolution with a chirp, evaluated with power of two
+// FFTs.
+func bluestein(x []complex128, inverse bool) []complex128 {
+	n := len(x)
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+
+	// chirp[k] = exp(sign i pi k^2 / n), with k^2 reduced mod 2n for accuracy
+	chirp := make([]complex128, n)
+	for k := range chirp {
+		k2 := (k * k) % (2 * n)
+		chirp[k] = cmplx.Rect(1, sign*math.Pi*float64(k2)/float64(n))
+	}
+
+	m := NextPow2(2*n - 1)
+	a := make([]complex128, m)
+	b := make([]complex128, m)
+	for k := 0; k < n; k++ {
+		a[k] = x[k] * chirp[k]
+	}
+	b[0] = cmplx.Conj(chirp[0])
+	for k := 1; k < n; k++ {
+		b[k] = cmplx.Conj(chirp[k])
+		b[m-k] = b[k]
+	}
+	radix2FFT(a, false)
+	radix2FFT(b, false)
+	for k := range a {
+		a[k] *= b[k]
+	}
+	radix2FFT(a, true)
+
+	X := make([]complex128, n)
+	for k := range X {
+		X[k] = a[k] * chirp[k]
+		if inverse {
+			X[k] /= complex(float64(n), 0)
+		}
+	}
+	return X
+}
+
+// radix2FFT computes the discrete Fourier transform of x in place using an
+// iterative radix-2 algorithm. The length of x must be a power of two.
+func radix2FFT(x []complex128, inverse bool) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	// bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		w := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		half := size >> 1
+		for start := 0; start < n; start += size {
+			wk := complex(1, 0)
+			for k := 0; k < half; k++ {
+				u := x[start+k]
+				v := x[start+k+half] * wk
+				x[start+k] = u + v
+				x[start+k+half] = u - v
+				wk *= w
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range x {
+			x[i] *= scale
+		}
+	}
+}
+
+// maskGain returns the gain of a mask spanning 0 to the Nyquist frequency at
+// bin k of bins one-sided bins.
+func maskGain(mask DataSet, k, bins int) float64 {
+	if len(mask) == bins {
+		return mask[k]
+	}
+	if len(mask) == 1 || bins == 1 {
+		return mask[0]
+	}
+	pos := float64(k) * float64(len(mask)-1) / float64(bins-1)
+	i := int(pos)
+	if i >= len(mask)-1 {
+		return mask[len(mask)-1]
+	}
+	frac := pos - float64(i)
+	return mask[i]*(1-frac) + mask[i+1]*frac
+}
+
+// realToComplex copies a real signal into a zero-padded complex buffer of
+// length n.
+func realToComplex(X []float64, n int) []complex128 {
+	c := make([]complex128, n)
+	for i := 0; i < len(X) && i < n; i++ {
+		c[i] = complex(X[i], 0)
+	}
+	return c
+}
+
+// fftConvolve returns the full linear convolution of a and b computed by the
+// current backend.
+func fftConvolve(a, b []float64) []float64 {
+	return backend.Convolve(a, b)
+}
@@ -0,0 +1,243 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+	"runtime"
+	"sync"
+)
+
+// FFTPlan holds the twiddle factors, permutation and scratch buffers for
+// transforms of one length, so repeated transforms neither recompute them nor
+// allocate. Power of two lengths use a radix-2 FFT and other lengths use
+// Bluestein's algorithm on an inner power of two plan. When a Backend other
+// than CPUBackend is set, the plan hands every transform to it instead. A
+// plan is not safe for concurrent use; give each goroutine its own.
+type FFTPlan struct {
+	n       int
+	twiddle []complex128
+	rev     []int
+
+	// Bluestein state for lengths that are not a power of two
+	inner   *FFTPlan
+	chirp   []complex128
+	kernel  []complex128
+	scratch []complex128
+
+	// spare holds the full transform for ForwardReal
+	spare []complex128
+}
+
+// NewFFTPlan creates a plan for transforms of length n. A plan of length
+// zero transforms nothing.
+func NewFFTPlan(n int) *FFTPlan {
+	if n < 0 {
+		log.Fatalf("NewFFTPlan requires a non-negative length, got %d", n)
+	}
+	p := &FFTPlan{n: n}
+	if n == 0 {
+		return p
+	}
+	if isPow2(n) {
+		p.twiddle = make([]complex128, n/2)
+		for k := range p.twiddle {
+			p.twiddle[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+		}
+		p.rev = make([]int, n)
+		bits := 0
+		for 1<<uint(bits) < n {
+			bits++
+		}
+		for i := range p.rev {
+			r := 0
+			for b := 0; b < bits; b++ {
+				r |= (i >> uint(b) & 1) << uint(bits-1-b)
+			}
+			p.rev[i] = r
+		}
+		return p
+	}
+
+	m := NextPow2(2*n - 1)
+	p.inner = NewFFTPlan(m)
+	p.chirp = make([]complex128, n)
+	for k := range p.chirp {
+		k2 := (k * k) % (2 * n)
+		p.chirp[k] = cmplx.Rect(1, -math.Pi*float64(k2)/float64(n))
+	}
+	b := make([]complex128, m)
+	b[0] = cmplx.Conj(p.chirp[0])
+	for k := 1; k < n; k++ {
+		b[k] = cmplx.Conj(p.chirp[k])
+		b[m-k] = b[k]
+	}
+	p.kernel = make([]complex128, m)
+	p.inner.Forward(p.kernel, b)
+	p.scratch = make([]complex128, m)
+	return p
+}
+
+// Len returns the transform length of the plan.
+func (p *FFTPlan) Len() int {
+	return p.n
+}
+
+// Forward writes the discrete Fourier transform of src to dst. Both must have
+// the plan's length; they may be the same slice.
+func (p *FFTPlan) Forward(dst, src []complex128) {
+	p.transform(dst, src, false)
+}
+
+// Inverse writes the inverse discrete Fourier transform of src, scaled by
+// 1/N, to dst. Both must have the plan's length; they may be the same slice.
+func (p *FFTPlan) Inverse(dst, src []complex128) {
+	p.transform(dst, src, true)
+	scale := complex(1/float64(p.n), 0)
+	for i := range dst[:p.n] {
+		dst[i] *= scale
+	}
+}
+
+// ForwardReal writes the non-negative frequency bins 0 through N/2 of the
+// transform of the real signal src to dst, which must hold N/2+1 values. A
+// shorter src is zero padded.
+func (p *FFTPlan) ForwardReal(dst []complex128, src []float64) {
+	if p.n == 0 {
+		return
+	}
+	if len(p.spare) < p.n {
+		p.spare = make([]complex128, p.n)
+	}
+	buf := p.spare[:p.n]
+	for i := range buf {
+		buf[i] = 0
+		if i < len(src) {
+			buf[i] = complex(src[i], 0)
+		}
+	}
+	p.transform(buf, buf, false)
+	copy(dst, buf[:p.n/2+1])
+}
+
+// transform computes the unscaled forward or inverse transform.
+func (p *FFTPlan) transform(dst, src []complex128, inverse bool) {
+	n := p.n
+	if n == 0 {
+		return
+	}
+	if offloaded() {
+		y := backend.FFT(src[:n], inverse)
+		if inverse {
+			// the backend scales the inverse by 1/N
+			for i := range y {
+				y[i] *= complex(float64(n), 0)
+			}
+		}
+		copy(dst, y)
+		return
+	}
+	if p.inner != nil {
+		p.bluestein(dst, src, inverse)
+		return
+	}
+
+	if &dst[0] == &src[0] {
+		for i, r := range p.rev {
+			if i < r {
+				dst[i], dst[r] = dst[r], dst[i]
+			}
+		}
+	} else {
+		for i, r := range p.rev {
+			dst[r] = src[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half := size >> 1
+		stride := n / size
+		for start := 0; start < n; start += size {
+			for k := 0; k < half; k++ {
+				w := p.twiddle[k*stride]
+				if inverse {
+					w = cmplx.Conj(w)
+				}
+				a := dst[start+k]
+				b := dst[start+k+half] * w
+				dst[start+k] = a + b
+				dst[start+k+half] = a - b
+			}
+		}
+	}
+}
+
+// bluestein computes the unscaled transform as a chirp convolution. The
+// inverse transform is the conjugate of the forward transform of the
+// conjugate input.
+func (p *FFTPlan) bluestein(dst, src []complex128, inverse bool) {
+	n := p.n
+	a := p.scratch
+	for k := range a {
+		a[k] = 0
+	}
+	for k := 0; k < n; k++ {
+		x := src[k]
+		if inverse {
+			x = cmplx.Conj(x)
+		}
+		a[k] = x * p.chirp[k]
+	}
+	p.inner.Forward(a, a)
+	for k := range a {
+		a[k] *= p.kernel[k]
+	}
+	p.inner.Inverse(a, a)
+	for k := 0; k < n; k++ {
+		y := a[k] * p.chirp[k]
+		if inverse {
+			y = cmplx.Conj(y)
+		}
+		dst[k] = y
+	}
+}
+
+// FFTBatch returns the non-negative frequency bins 0 through N/2 of the
+// transform of each frame, like RFFT, spreading the frames across one
+// goroutine per CPU. Every frame must have the same length.
+func FFTBatch(frames [][]float64) [][]complex128 {
+	out := make([][]complex128, len(frames))
+	if len(frames) == 0 {
+		return out
+	}
+	n := len(frames[0])
+	for _, frame := range frames {
+		if len(frame) != n {
+			log.Fatal("FFTBatch requires frames of equal length")
+		}
+	}
+
+	bins := n/2 + 1
+	if n == 0 {
+		bins = 0
+	}
+
+	workers := runtime.NumCPU()
+	if workers > len(frames) {
+		workers = len(frames)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			plan := NewFFTPlan(n)
+			for i := w; i < len(frames); i += workers {
+				out[i] = make([]complex128, bins)
+				plan.ForwardReal(out[i], frames[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+	return out
+}
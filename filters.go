@@ -1,6 +1,10 @@
 package dsp
 
-import "math"
+import (
+	"log"
+	"math"
+	"math/cmplx"
+)
 
 // NewLowPassFilter creates a new low-pass filter
 func NewLowPassFilter(fC, fS float64) *Filter {
@@ -69,6 +73,285 @@ func NewBandPassFilter(fC, bw, fS float64) *Filter {
 	return &Filter{B, A}
 }
 
+// NewBandStopFilter creates a new band-stop filter rejecting a band of width bw
+// centered on fC.
+func NewBandStopFilter(fC, bw, fS float64) *Filter {
+	return NewNotchFilter(fC, fC/bw, fS)
+}
+
+// NewNotchFilter creates a new notch filter with a zero of transmission at fC.
+// The quality factor Q sets the width of the notch, fC/Q between the -3 dB
+// points, so a high Q such as 30 removes mains hum at 50 or 60 Hz while
+// leaving neighbouring frequencies untouched.
+func NewNotchFilter(fC, Q, fS float64) *Filter {
+	wcT := 2 * math.Pi * fC / fS
+	K := math.Tan(wcT / 2)
+	K2 := K * K
+
+	// all coeff denoms are the same
+	denom := (1 + (1/Q)*K + K2)
+
+	b0 := 1.0
+	b1 := (2 * (K2 - 1)) / denom
+	b2 := (1 - (1/Q)*K + K2) / denom
+	a0 := (1 + K2) / denom
+	a1 := (2 * (K2 - 1)) / denom
+	a2 := (1 + K2) / denom
+
+	A := []float64{a0, a1, a2}
+	B := []float64{b0, b1, b2}
+
+	return &Filter{B, A}
+}
+
+// NewDCBlocker creates a new DC blocking filter, y[n] = x[n] - x[n-1] +
+// r y[n-1], with a zero at DC and a pole at r just inside the unit circle.
+// The closer r is to 1, such as 0.995, the narrower the notch around DC and
+// the slower the filter settles after a step in the offset. The -3 dB corner
+// is at about (1-r) fS / (2 pi).
+func NewDCBlocker(r float64) *Filter {
+	A := []float64{1, -1}
+	B := []float64{1, -r}
+
+	return &Filter{B, A}
+}
+
+// NewFeedforwardComb creates a new feedforward comb filter, y[n] = x[n] +
+// gain x[n-delay], which adds a delayed copy of the input. Its response has
+// notches (for positive gain, at odd multiples of fS/(2 delay)) evenly
+// spaced in frequency. The delay must be at least one sample.
+func NewFeedforwardComb(delay int, gain float64) *Filter {
+	if delay < 1 {
+		log.Fatalf("NewFeedforwardComb requires a delay of at least 1, got %d", delay)
+	}
+	A := make([]float64, delay+1)
+	B := make([]float64, delay+1)
+	A[0], A[delay] = 1, gain
+	B[0] = 1
+
+	return &Filter{B, A}
+}
+
+// NewFeedbackComb creates a new feedback comb filter, y[n] = x[n] +
+// gain y[n-delay], which recirculates the output to give a train of decaying
+// echoes and resonant peaks at multiples of fS/delay. The gain must be less
+// than 1 in magnitude for the filter to be stable, and the delay must be at
+// least one sample.
+func NewFeedbackComb(delay int, gain float64) *Filter {
+	if delay < 1 {
+		log.Fatalf("NewFeedbackComb requires a delay of at least 1, got %d", delay)
+	}
+	A := make([]float64, delay+1)
+	B := make([]float64, delay+1)
+	A[0] = 1
+	B[0], B[delay] = 1, -gain
+
+	return &Filter{B, A}
+}
+
+// NewFirstOrderAllPass creates a new first-order all-pass filter. It passes
+// every frequency at unit gain while shifting the phase from 0 at DC through
+// -90 degrees at fC to -180 degrees at Nyquist.
+func NewFirstOrderAllPass(fC, fS float64) *Filter {
+	K := math.Tan(math.Pi * fC / fS)
+	c := (K - 1) / (K + 1)
+
+	A := []float64{c, 1}
+	B := []float64{1, c}
+
+	return &Filter{B, A}
+}
+
+// NewAllPassFilter creates a new second-order all-pass filter. The phase
+// falls from 0 at DC through -180 degrees at fC to -360 degrees at Nyquist,
+// and a higher Q makes the transition around fC steeper.
+func NewAllPassFilter(fC, Q, fS float64) *Filter {
+	wcT := 2 * math.Pi * fC / fS
+	K := math.Tan(wcT / 2)
+	K2 := K * K
+
+	// all coeff denoms are the same
+	denom := (1 + (1/Q)*K + K2)
+
+	b0 := 1.0
+	b1 := (2 * (K2 - 1)) / denom
+	b2 := (1 - (1/Q)*K + K2) / denom
+	a0 := b2
+	a1 := b1
+	a2 := b0
+
+	A := []float64{a0, a1, a2}
+	B := []float64{b0, b1, b2}
+
+	return &Filter{B, A}
+}
+
+// NewLowShelfFilter creates a new low-shelf filter that boosts or cuts the
+// frequencies below fC by gain dB, leaving those above unchanged. The slope S
+// sets the steepness of the transition; 1 is the steepest without overshoot.
+func NewLowShelfFilter(fC, gain, S, fS float64) *Filter {
+	return newShelfFilter(fC, gain, S, fS, false)
+}
+
+// NewHighShelfFilter creates a new high-shelf filter that boosts or cuts the
+// frequencies above fC by gain dB, leaving those below unchanged.
+func NewHighShelfFilter(fC, gain, S, fS float64) *Filter {
+	return newShelfFilter(fC, gain, S, fS, true)
+}
+
+// NewPeakingEQ creates a new peaking equalizer that boosts or cuts a band
+// around fC by gain dB, with the width of the band set by Q. It is the
+// building block of a parametric equalizer.
+func NewPeakingEQ(fC, Q, gain, fS float64) *Filter {
+	amp := math.Pow(10, gain/40)
+	wcT := 2 * math.Pi * fC / fS
+	cos := math.Cos(wcT)
+	alpha := math.Sin(wcT) / (2 * Q)
+
+	// all coeff denoms are the same
+	denom := 1 + alpha/amp
+
+	b0 := 1.0
+	b1 := -2 * cos / denom
+	b2 := (1 - alpha/amp) / denom
+	a0 := (1 + alpha*amp) / denom
+	a1 := -2 * cos / denom
+	a2 := (1 - alpha*amp) / denom
+
+	A := []float64{a0, a1, a2}
+	B := []float64{b0, b1, b2}
+
+	return &Filter{B, A}
+}
+
+// newShelfFilter designs a shelf filter from the RBJ audio EQ cookbook.
+func newShelfFilter(fC, gain, S, fS float64, high bool) *Filter {
+	amp := math.Pow(10, gain/40)
+	wcT := 2 * math.Pi * fC / fS
+	cos := math.Cos(wcT)
+	alpha := math.Sin(wcT) / 2 * math.Sqrt((amp+1/amp)*(1/S-1)+2)
+	beta := 2 * math.Sqrt(amp) * alpha
+
+	// a high shelf is a low shelf with the sign of the cosine flipped
+	sign := 1.0
+	if high {
+		sign = -1
+	}
+
+	// all coeff denoms are the same
+	denom := (amp + 1) + sign*(amp-1)*cos + beta
+
+	b0 := 1.0
+	b1 := -2 * sign * ((amp - 1) + sign*(amp+1)*cos) / denom
+	b2 := ((amp + 1) + sign*(amp-1)*cos - beta) / denom
+	a0 := amp * ((amp + 1) - sign*(amp-1)*cos + beta) / denom
+	a1 := 2 * sign * amp * ((amp - 1) - sign*(amp+1)*cos) / denom
+	a2 := amp * ((amp + 1) - sign*(amp-1)*cos - beta) / denom
+
+	A := []float64{a0, a1, a2}
+	B := []float64{b0, b1, b2}
+
+	return &Filter{B, A}
+}
+
+// FilterType selects the response of a designed filter.
+type FilterType int
+
+const (
+	// LowPassFilter passes frequencies below the cutoff.
+	LowPassFilter FilterType = iota
+
+	// HighPassFilter passes frequencies above the cutoff.
+	HighPassFilter
+
+	// BandPassFilter passes frequencies within the band.
+	BandPassFilter
+
+	// BandStopFilter rejects frequencies within the band.
+	BandStopFilter
+)
+
+// NewButterworth designs a low-pass or high-pass Butterworth filter of the
+// given order with its -3 dB point at fC. The response is maximally flat in
+// the passband. Band types are designed with NewButterworthBand.
+func NewButterworth(order int, fC, fS float64, kind FilterType) SOS {
+	checkEdgeKind("NewButterworth", "NewButterworthBand", kind)
+	return butterworth(order).digital("NewButterworth", fC, 0, fS, kind)
+}
+
+// NewButterworthBand designs a band-pass or band-stop Butterworth filter with
+// its -3 dB points bw apart, centered on fC. The order is that of the low-pass
+// prototype, so the filter has twice as many poles.
+func NewButterworthBand(order int, fC, bw, fS float64, kind FilterType) SOS {
+	return butterworth(order).digital("NewButterworthBand", fC, bw, fS, kind)
+}
+
+// NewChebyshev1 designs a low-pass or high-pass Chebyshev type I filter of the
+// given order with ripple dB of equiripple in the passband, which ends at fC.
+// It rolls off faster than a Butterworth filter of the same order. Band types
+// are designed with NewChebyshev1Band.
+func NewChebyshev1(order int, ripple, fC, fS float64, kind FilterType) SOS {
+	checkEdgeKind("NewChebyshev1", "NewChebyshev1Band", kind)
+	return chebyshev1(order, ripple).digital("NewChebyshev1", fC, 0, fS, kind)
+}
+
+// NewChebyshev1Band designs a band-pass or band-stop Chebyshev type I filter
+// with passband edges bw apart, centered on fC.
+func NewChebyshev1Band(order int, ripple, fC, bw, fS float64, kind FilterType) SOS {
+	return chebyshev1(order, ripple).digital("NewChebyshev1Band", fC, bw, fS, kind)
+}
+
+// NewChebyshev2 designs a low-pass or high-pass Chebyshev type II filter of
+// the given order whose stopband, starting at fC, is attenuated by at least
+// attenuation dB. The passband is maximally flat. Band types are designed
+// with NewChebyshev2Band.
+func NewChebyshev2(order int, attenuation, fC, fS float64, kind FilterType) SOS {
+	checkEdgeKind("NewChebyshev2", "NewChebyshev2Band", kind)
+	return chebyshev2(order, attenuation).digital("NewChebyshev2", fC, 0, fS, kind)
+}
+
+// NewChebyshev2Band designs a band-pass or band-stop Chebyshev type II filter
+// with stopband edges bw apart, centered on fC.
+func NewChebyshev2Band(order int, attenuation, fC, bw, fS float64, kind FilterType) SOS {
+	return chebyshev2(order, attenuation).digital("NewChebyshev2Band", fC, bw, fS, kind)
+}
+
+// NewBessel designs a low-pass or high-pass Bessel (Thomson) filter of the
+// given order with its -3 dB point at fC. Its group delay is nearly constant
+// through the passband, so pulses pass with little overshoot or ringing, at
+// the cost of a slower roll-off than a Butterworth filter. Band types are
+// designed with NewBesselBand.
+func NewBessel(order int, fC, fS float64, kind FilterType) SOS {
+	checkEdgeKind("NewBessel", "NewBesselBand", kind)
+	return bessel(order).digital("NewBessel", fC, 0, fS, kind)
+}
+
+// NewBesselBand designs a band-pass or band-stop Bessel filter with its -3 dB
+// points bw apart, centered on fC.
+func NewBesselBand(order int, fC, bw, fS float64, kind FilterType) SOS {
+	return bessel(order).digital("NewBesselBand", fC, bw, fS, kind)
+}
+
+// NewLinkwitzRiley designs a Linkwitz-Riley crossover of the given even order,
+// such as 2 (LR2) or 4 (LR4), splitting at fC. Each output is a Butterworth
+// filter of half the order applied twice, so both are -6 dB at fC and their
+// sum has a flat magnitude response. Where the two outputs would be out of
+// phase, as for LR2, the high-pass output is inverted.
+func NewLinkwitzRiley(order int, fC, fS float64) (SOS, SOS) {
+	if order < 2 || order%2 != 0 {
+		log.Fatalf("NewLinkwitzRiley requires an even order, got %d", order)
+	}
+	half := order / 2
+	low := append(NewButterworth(half, fC, fS, LowPassFilter), NewButterworth(half, fC, fS, LowPassFilter)...)
+	high := append(NewButterworth(half, fC, fS, HighPassFilter), NewButterworth(half, fC, fS, HighPassFilter)...)
+	if half%2 == 1 {
+		for i := range high[0].A {
+			high[0].A[i] = -high[0].A[i]
+		}
+	}
+	return low, high
+}
+
 // Filter contains the coefficients for a filter.
 type Filter struct {
 	B, A []float64
@@ -89,3 +372,23 @@ func (f Filter) Filter(X []float64) []float64 {
 	}
 	return Y
 }
+
+// Response returns the complex frequency response of the filter at freq.
+func (f Filter) Response(freq, fS float64) complex128 {
+	z := cmplx.Rect(1, -2*math.Pi*freq/fS)
+	var num, den complex128
+	for i := len(f.A) - 1; i >= 0; i-- {
+		num = num*z + complex(f.A[i], 0)
+	}
+	for i := len(f.B) - 1; i >= 0; i-- {
+		den = den*z + complex(f.B[i], 0)
+	}
+	return num / den
+}
+
+// FiltFilt executes the filter forwards and then backwards over the data,
+// giving zero phase distortion and squaring the magnitude response.
+func (f Filter) FiltFilt(X []float64) []float64 {
+	Y := f.Filter(X)
+	return DataSet(f.Filter(DataSet(Y).Reverse())).Reverse()
+}
@@ -0,0 +1,177 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// fingerprintNeighborhood is the number of frames and bins on each side
+	// of a spectral peak that it must exceed to be a landmark.
+	fingerprintNeighborhood = 10
+
+	// fingerprintPeaks is the largest number of landmarks kept per frame.
+	fingerprintPeaks = 5
+
+	// fingerprintFanOut is the number of later landmarks each landmark is
+	// paired with.
+	fingerprintFanOut = 5
+
+	// fingerprintZone is the largest distance in frames between the
+	// landmarks of a pair.
+	fingerprintZone = 64
+)
+
+// Fingerprint is a hash of a pair of spectral peaks, the frequency of each
+// and the time between them, together with the frame of the first peak.
+// Hashes are unaffected by level and robust to noise, and a recording shares
+// many of them at a consistent frame offset with any excerpt of it.
+type Fingerprint struct {
+	Hash  uint64
+	Frame int
+}
+
+// landmark is a spectral peak.
+type landmark struct {
+	frame, bin int
+}
+
+// Fingerprints computes the constellation map of the spectrogram, the peaks
+// that are the largest in their neighbourhood of time and frequency, and
+// hashes pairs of nearby peaks into fingerprints.
+func (s *Spectrogram) Fingerprints() []Fingerprint {
+	mag := s.Magnitude()
+	if len(mag) == 0 {
+		return nil
+	}
+	bins := len(mag[0])
+	w := fingerprintNeighborhood
+
+	// separable running maximum over frequency, then time
+	byFreq := make([][]float64, len(mag))
+	for t, frame := range mag {
+		byFreq[t] = make([]float64, bins)
+		for k := range frame {
+			m := 0.0
+			for j := maxInt(0, k-w); j <= minInt(bins-1, k+w); j++ {
+				m = math.Max(m, frame[j])
+			}
+			byFreq[t][k] = m
+		}
+	}
+
+	var peaks []landmark
+	for t, frame := range mag {
+		var candidates []landmark
+		for k, v := range frame {
+			if v == 0 {
+				continue
+			}
+			local := 0.0
+			for j := maxInt(0, t-w); j <= minInt(len(mag)-1, t+w); j++ {
+				local = math.Max(local, byFreq[j][k])
+			}
+			if v == local {
+				candidates = append(candidates, landmark{t, k})
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return frame[candidates[i].bin] > frame[candidates[j].bin]
+		})
+		if len(candidates) > fingerprintPeaks {
+			candidates = candidates[:fingerprintPeaks]
+		}
+		peaks = append(peaks, candidates...)
+	}
+
+	var prints []Fingerprint
+	for i, a := range peaks {
+		paired := 0
+		for _, b := range peaks[i+1:] {
+			dt := b.frame - a.frame
+			if dt > fingerprintZone || paired == fingerprintFanOut {
+				break
+			}
+			if dt == 0 {
+				continue
+			}
+			prints = append(prints, Fingerprint{
+				Hash:  uint64(a.bin)<<32 | uint64(b.bin)<<16 | uint64(dt),
+				Frame: a.frame,
+			})
+			paired++
+		}
+	}
+	return prints
+}
+
+// FingerprintMatch is a recording identified from its fingerprints.
+type FingerprintMatch struct {
+	// ID identifies the recording.
+	ID string
+
+	// Offset is the frame of the recording where the query starts.
+	Offset int
+
+	// Votes is the number of query hashes agreeing on the offset.
+	Votes int
+}
+
+// FingerprintIndex is a database of recording fingerprints to search.
+type FingerprintIndex struct {
+	hashes map[uint64][]indexEntry
+}
+
+// indexEntry is the occurrence of a hash in an indexed recording.
+type indexEntry struct {
+	id    string
+	frame int
+}
+
+// NewFingerprintIndex creates an empty fingerprint index.
+func NewFingerprintIndex() *FingerprintIndex {
+	return &FingerprintIndex{hashes: make(map[uint64][]indexEntry)}
+}
+
+// Add indexes the fingerprints of a recording under the given id.
+func (x *FingerprintIndex) Add(id string, prints []Fingerprint) {
+	for _, p := range prints {
+		x.hashes[p.Hash] = append(x.hashes[p.Hash], indexEntry{id, p.Frame})
+	}
+}
+
+// Match looks up the fingerprints of a query and returns the indexed
+// recordings that contain it, most votes first, with the best offset for
+// each. A query that matches nothing returns no results. The query must use
+// the same window size and hop as the indexed recordings.
+func (x *FingerprintIndex) Match(prints []Fingerprint) []FingerprintMatch {
+	type key struct {
+		id     string
+		offset int
+	}
+	votes := make(map[key]int)
+	for _, p := range prints {
+		for _, e := range x.hashes[p.Hash] {
+			votes[key{e.id, e.frame - p.Frame}]++
+		}
+	}
+
+	best := make(map[string]FingerprintMatch)
+	for k, v := range votes {
+		m, ok := best[k.id]
+		if !ok || v > m.Votes || (v == m.Votes && k.offset < m.Offset) {
+			best[k.id] = FingerprintMatch{ID: k.id, Offset: k.offset, Votes: v}
+		}
+	}
+	matches := make([]FingerprintMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Votes != matches[j].Votes {
+			return matches[i].Votes > matches[j].Votes
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
@@ -0,0 +1,150 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+)
+
+// DesignFIR designs a linear phase FIR filter with the given number of taps
+// by the window method: the ideal response of the filter type is truncated
+// and multiplied by the window, or a Hamming window if window is nil. Low-pass
+// and high-pass filters take one cutoff in Hz, band-pass and band-stop
+// filters the lower and upper edges of the band. High-pass and band-stop
+// filters must pass Nyquist, so their number of taps is rounded up to an odd
+// number. The taps are scaled for unit gain at DC, at Nyquist for high-pass
+// filters and at the center of the band for band-pass filters.
+func DesignFIR(taps int, cutoffs []float64, kind FilterType, window WindowFunc, fS float64) DataSet {
+	if window == nil {
+		window = Hamming
+	}
+	want := 1
+	if kind == BandPassFilter || kind == BandStopFilter {
+		want = 2
+	}
+	if len(cutoffs) != want {
+		log.Fatalf("DesignFIR requires %d cutoffs for filter type %d, got %d", want, kind, len(cutoffs))
+	}
+	for _, fC := range cutoffs {
+		if fC <= 0 || fC >= fS/2 {
+			log.Fatalf("DesignFIR requires cutoffs between 0 and %v, got %v", fS/2, fC)
+		}
+	}
+	if (kind == HighPassFilter || kind == BandStopFilter) && taps%2 == 0 {
+		taps++
+	}
+
+	// the ideal responses as sums of low-pass filters and an impulse
+	center := float64(taps-1) / 2
+	lowPass := func(fC, t float64) float64 {
+		if t == 0 {
+			return 2 * fC / fS
+		}
+		return math.Sin(2*math.Pi*fC/fS*t) / (math.Pi * t)
+	}
+	w := Symmetric(window, taps)
+	h := make(DataSet, taps)
+	for i := range h {
+		t := float64(i) - center
+		var impulse float64
+		if t == 0 {
+			impulse = 1
+		}
+		switch kind {
+		case LowPassFilter:
+			h[i] = lowPass(cutoffs[0], t)
+		case HighPassFilter:
+			h[i] = impulse - lowPass(cutoffs[0], t)
+		case BandPassFilter:
+			h[i] = lowPass(cutoffs[1], t) - lowPass(cutoffs[0], t)
+		case BandStopFilter:
+			h[i] = impulse - lowPass(cutoffs[1], t) + lowPass(cutoffs[0], t)
+		default:
+			log.Fatalf("DesignFIR does not support filter type %d", kind)
+		}
+		h[i] *= w[i]
+	}
+
+	var f float64
+	switch kind {
+	case HighPassFilter:
+		f = fS / 2
+	case BandPassFilter:
+		f = (cutoffs[0] + cutoffs[1]) / 2
+	}
+	gain := cmplx.Abs(firResponse(h, f, fS))
+	for i := range h {
+		h[i] /= gain
+	}
+	return h
+}
+
+// FIRFilter is a streaming FIR filter, such as one designed by DesignFIR.
+// Short kernels are convolved directly and long ones with an
+// OverlapSaveFilter, chosen when the filter is created.
+type FIRFilter struct {
+	Taps DataSet
+
+	history []float64
+	fast    *OverlapSaveFilter
+}
+
+// NewFIRFilter creates a streaming filter with the given taps.
+func NewFIRFilter(taps DataSet) *FIRFilter {
+	if len(taps) > convolveDirectLimit {
+		return &FIRFilter{Taps: taps, fast: NewOverlapSaveFilter(taps)}
+	}
+	return &FIRFilter{Taps: taps, history: make([]float64, len(taps)-1)}
+}
+
+// Delay returns the group delay of a linear phase filter in samples.
+func (f *FIRFilter) Delay() float64 {
+	return float64(len(f.Taps)-1) / 2
+}
+
+// Response returns the complex frequency response at the given frequency.
+func (f *FIRFilter) Response(freq, fS float64) complex128 {
+	return firResponse(f.Taps, freq, fS)
+}
+
+// Process filters a block of samples, continuing from the state left by the
+// previous block.
+func (f *FIRFilter) Process(X []float64) []float64 {
+	if f.fast != nil {
+		return f.fast.Process(X)
+	}
+	h := len(f.history)
+	buf := make([]float64, h+len(X))
+	copy(buf, f.history)
+	copy(buf[h:], X)
+
+	Y := make([]float64, len(X))
+	for i := range Y {
+		var y float64
+		for k, c := range f.Taps {
+			y += c * buf[h+i-k]
+		}
+		Y[i] = y
+	}
+	copy(f.history, buf[len(buf)-h:])
+	return Y
+}
+
+// Reset clears the filter history.
+func (f *FIRFilter) Reset() {
+	if f.fast != nil {
+		f.fast.Reset()
+	}
+	for i := range f.history {
+		f.history[i] = 0
+	}
+}
+
+// firResponse evaluates the frequency response of FIR taps.
+func firResponse(h DataSet, freq, fS float64) complex128 {
+	var y complex128
+	for i, c := range h {
+		y += complex(c, 0) * cmplx.Exp(complex(0, -2*math.Pi*freq/fS*float64(i)))
+	}
+	return y
+}
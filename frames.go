@@ -0,0 +1,58 @@
+package dsp
+
+// Frames is a multichannel signal stored as a sequence of frames, where each
+// frame holds one sample per channel.
+type Frames [][]float64
+
+// NewFrames interleaves the given channels into frames. The result is as long
+// as the shortest channel.
+func NewFrames(channels ...DataSet) Frames {
+	if len(channels) == 0 {
+		return Frames{}
+	}
+	n := len(channels[0])
+	for _, ch := range channels {
+		if len(ch) < n {
+			n = len(ch)
+		}
+	}
+	frames := make([][]float64, n)
+	for t := 0; t < n; t++ {
+		frames[t] = make([]float64, len(channels))
+		for c, ch := range channels {
+			frames[t][c] = ch[t]
+		}
+	}
+	return frames
+}
+
+// Len returns the number of frames.
+func (f Frames) Len() int {
+	return len(f)
+}
+
+// Channels returns the number of channels.
+func (f Frames) Channels() int {
+	if len(f) == 0 {
+		return 0
+	}
+	return len(f[0])
+}
+
+// Channel returns a copy of a single channel.
+func (f Frames) Channel(c int) DataSet {
+	values := make([]float64, len(f))
+	for t := range f {
+		values[t] = f[t][c]
+	}
+	return values
+}
+
+// Split returns a copy of every channel.
+func (f Frames) Split() []DataSet {
+	channels := make([]DataSet, f.Channels())
+	for c := range channels {
+		channels[c] = f.Channel(c)
+	}
+	return channels
+}
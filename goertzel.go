@@ -0,0 +1,81 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Goertzel computes the DFT of a stream at a single frequency using the
+// Goertzel recurrence. It costs one multiply per sample, far less than an FFT
+// when only a few tones are of interest, and the frequency need not fall on an
+// FFT bin.
+type Goertzel struct {
+	omega  float64
+	coeff  float64
+	s1, s2 float64
+	n      int
+}
+
+// NewGoertzel creates a detector for the frequency freq at sample rate fS.
+func NewGoertzel(freq, fS float64) *Goertzel {
+	omega := 2 * math.Pi * freq / fS
+	return &Goertzel{omega: omega, coeff: 2 * math.Cos(omega)}
+}
+
+// Update adds a single sample.
+func (g *Goertzel) Update(x float64) {
+	s := x + g.coeff*g.s1 - g.s2
+	g.s2 = g.s1
+	g.s1 = s
+	g.n++
+}
+
+// Process adds a block of samples.
+func (g *Goertzel) Process(X []float64) {
+	for _, x := range X {
+		g.Update(x)
+	}
+}
+
+// Value returns the DFT of the samples seen since the last reset at the
+// detector frequency.
+func (g *Goertzel) Value() complex128 {
+	if g.n == 0 {
+		return 0
+	}
+	y := complex(g.s1, 0) - cmplx.Exp(complex(0, -g.omega))*complex(g.s2, 0)
+	return y * cmplx.Exp(complex(0, -g.omega*float64(g.n-1)))
+}
+
+// Power returns the squared magnitude of the DFT at the detector frequency.
+func (g *Goertzel) Power() float64 {
+	return g.s1*g.s1 + g.s2*g.s2 - g.coeff*g.s1*g.s2
+}
+
+// Amplitude returns the estimated amplitude of a sine at the detector
+// frequency.
+func (g *Goertzel) Amplitude() float64 {
+	if g.n == 0 {
+		return 0
+	}
+	return 2 * math.Sqrt(math.Max(0, g.Power())) / float64(g.n)
+}
+
+// Count returns the number of samples seen since the last reset.
+func (g *Goertzel) Count() int {
+	return g.n
+}
+
+// Reset clears the detector state.
+func (g *Goertzel) Reset() {
+	g.s1, g.s2 = 0, 0
+	g.n = 0
+}
+
+// Goertzel returns the squared magnitude of the DFT of the data set at the
+// frequency freq.
+func (d DataSet) Goertzel(freq, fS float64) float64 {
+	g := NewGoertzel(freq, fS)
+	g.Process(d)
+	return g.Power()
+}
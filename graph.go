@@ -0,0 +1,172 @@
+package dsp
+
+import "fmt"
+
+// NodeID identifies a node of a Graph.
+type NodeID int
+
+// graphNode is a processor, mixer or the input of a Graph.
+type graphNode struct {
+	proc   Processor
+	inputs []NodeID
+
+	// up and down give the sample rate as a reduced fraction of the graph
+	// input rate
+	up, down int
+
+	// pending holds samples from each input of a mixer not yet matched by
+	// the other inputs
+	pending [][]float64
+	out     []float64
+}
+
+// Graph connects processors into a directed acyclic graph. The output of a
+// node can feed any number of others, and a mixer sums several branches back
+// into one. The sample rate of every node is tracked from the input rate
+// through each RateChanger, so branches can run at different rates after
+// decimation, and connections between nodes at different rates are rejected
+// when they are made. Nodes can only be connected to nodes already in the
+// graph, so it cannot contain cycles.
+type Graph struct {
+	// SampleRate is the sample rate of the graph input in Hz.
+	SampleRate float64
+
+	nodes   []*graphNode
+	outputs []NodeID
+}
+
+// NewGraph creates a graph whose input is sampled at the given rate.
+func NewGraph(fS float64) *Graph {
+	return &Graph{SampleRate: fS, nodes: []*graphNode{{up: 1, down: 1}}}
+}
+
+// Input returns the node carrying the samples passed to Process.
+func (g *Graph) Input() NodeID {
+	return 0
+}
+
+// Add adds a processor fed by the output of an existing node.
+func (g *Graph) Add(p Processor, from NodeID) (NodeID, error) {
+	if err := g.check(from); err != nil {
+		return 0, err
+	}
+	src := g.nodes[from]
+	up, down := src.up, src.down
+	if r, ok := p.(RateChanger); ok {
+		u, d := r.RateRatio()
+		if u <= 0 || d <= 0 {
+			return 0, fmt.Errorf("dsp: invalid rate ratio %d/%d for %T", u, d, p)
+		}
+		up, down = reduceRatio(up*u, down*d)
+	}
+	g.nodes = append(g.nodes, &graphNode{proc: p, inputs: []NodeID{from}, up: up, down: down})
+	return NodeID(len(g.nodes) - 1), nil
+}
+
+// Mix adds a node summing the outputs of existing nodes, which must all run
+// at the same sample rate. Branches whose outputs arrive in blocks of
+// different lengths, such as after decimators with different delays, are
+// buffered so that the samples summed are always aligned.
+func (g *Graph) Mix(inputs ...NodeID) (NodeID, error) {
+	if len(inputs) == 0 {
+		return 0, fmt.Errorf("dsp: mixer has no inputs")
+	}
+	for _, n := range inputs {
+		if err := g.check(n); err != nil {
+			return 0, err
+		}
+	}
+	first := g.nodes[inputs[0]]
+	for _, n := range inputs[1:] {
+		if node := g.nodes[n]; node.up != first.up || node.down != first.down {
+			return 0, fmt.Errorf("dsp: cannot mix node %d at %g Hz with node %d at %g Hz",
+				inputs[0], g.Rate(inputs[0]), n, g.Rate(n))
+		}
+	}
+	g.nodes = append(g.nodes, &graphNode{
+		inputs:  append([]NodeID(nil), inputs...),
+		up:      first.up,
+		down:    first.down,
+		pending: make([][]float64, len(inputs)),
+	})
+	return NodeID(len(g.nodes) - 1), nil
+}
+
+// Output adds a node to the outputs returned by Process.
+func (g *Graph) Output(n NodeID) error {
+	if err := g.check(n); err != nil {
+		return err
+	}
+	g.outputs = append(g.outputs, n)
+	return nil
+}
+
+// Rate returns the sample rate of a node in Hz.
+func (g *Graph) Rate(n NodeID) float64 {
+	node := g.nodes[n]
+	return g.SampleRate * float64(node.up) / float64(node.down)
+}
+
+// Process runs a block of input samples through the graph and returns the
+// block produced at each output, in the order they were added.
+func (g *Graph) Process(X []float64) [][]float64 {
+	g.nodes[0].out = X
+	for _, node := range g.nodes[1:] {
+		if node.proc != nil {
+			node.out = node.proc.Process(g.nodes[node.inputs[0]].out)
+			continue
+		}
+
+		// mixer: sum the samples available from every input
+		n := -1
+		for i, in := range node.inputs {
+			node.pending[i] = append(node.pending[i], g.nodes[in].out...)
+			if n < 0 || len(node.pending[i]) < n {
+				n = len(node.pending[i])
+			}
+		}
+		node.out = make([]float64, n)
+		for i, p := range node.pending {
+			for j := 0; j < n; j++ {
+				node.out[j] += p[j]
+			}
+			node.pending[i] = append(p[:0], p[n:]...)
+		}
+	}
+
+	Y := make([][]float64, len(g.outputs))
+	for i, n := range g.outputs {
+		Y[i] = g.nodes[n].out
+	}
+	return Y
+}
+
+// Reset clears the state of every processor and mixer.
+func (g *Graph) Reset() {
+	for _, node := range g.nodes {
+		if node.proc != nil {
+			node.proc.Reset()
+		}
+		for i := range node.pending {
+			node.pending[i] = node.pending[i][:0]
+		}
+		node.out = nil
+	}
+}
+
+// check returns an error if n is not a node of the graph.
+func (g *Graph) check(n NodeID) error {
+	if n < 0 || int(n) >= len(g.nodes) {
+		return fmt.Errorf("dsp: graph has no node %d", n)
+	}
+	return nil
+}
+
+// reduceRatio divides both terms of a ratio by their greatest common divisor.
+func reduceRatio(a, b int) (int, int) {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x, b / x
+}
@@ -0,0 +1,173 @@
+package dsp
+
+import "math"
+
+// HalfBand designs a half-band low-pass FIR filter, cut off at a quarter of
+// the sample rate. Every other tap apart from the center is exactly zero, and
+// Decimator and Interpolator skip them, so decimating or interpolating by two
+// costs about a quarter of its length per sample at the higher rate. The
+// number of taps is rounded up to the form 4k + 3 so the outermost taps are
+// nonzero.
+func HalfBand(taps int) DataSet {
+	if taps < 3 {
+		taps = 3
+	}
+	for taps%4 != 3 {
+		taps++
+	}
+	w := Symmetric(Hamming, taps)
+	h := make([]float64, taps)
+	center := (taps - 1) / 2
+	var sum float64
+	for i := range h {
+		t := i - center
+		if t%2 != 0 {
+			h[i] = math.Sin(math.Pi*float64(t)/2) / (math.Pi * float64(t)) * w[i]
+			sum += h[i]
+		}
+	}
+
+	// the odd taps sum to 1/2 and the center is exactly 1/2 for unit DC gain
+	for i := range h {
+		h[i] *= 0.5 / sum
+	}
+	h[center] = 0.5
+	return h
+}
+
+// Interpolator is a streaming polyphase upsampler and FIR anti-imaging
+// filter. Each input sample produces Factor output samples, each computed from
+// one polyphase branch of the filter. Zero taps are skipped, so the branch of
+// a half-band filter holding its center costs one multiply. Taps must not be
+// changed after the interpolator is created.
+type Interpolator struct {
+	Factor int
+	Taps   DataSet
+
+	phases  [][]int
+	history []float64
+}
+
+// NewInterpolator creates an interpolator by the given factor with a windowed
+// sinc anti-imaging filter of the given number of taps.
+func NewInterpolator(factor, taps int) *Interpolator {
+	return NewInterpolatorFIR(factor, sincLowPass(taps, 0.5/float64(factor)))
+}
+
+// NewInterpolatorFIR creates an interpolator by the given factor with a custom
+// anti-imaging filter designed for unit DC gain at the output rate.
+func NewInterpolatorFIR(factor int, taps DataSet) *Interpolator {
+	phases := make([][]int, factor)
+	for phase := range phases {
+		phases[phase] = nonzeroTaps(taps, phase, factor)
+	}
+	return &Interpolator{
+		Factor:  factor,
+		Taps:    taps,
+		phases:  phases,
+		history: make([]float64, (len(taps)+factor-1)/factor),
+	}
+}
+
+// Process upsamples and filters a block of samples, continuing from the state
+// left by the previous block.
+func (p *Interpolator) Process(X []float64) []float64 {
+	L := p.Factor
+	Y := make([]float64, 0, len(X)*L)
+	for _, x := range X {
+		copy(p.history[1:], p.history)
+		p.history[0] = x
+		for phase := 0; phase < L; phase++ {
+			var y float64
+			for _, k := range p.phases[phase] {
+				y += p.Taps[k] * p.history[k/L]
+			}
+			Y = append(Y, float64(L)*y)
+		}
+	}
+	return Y
+}
+
+// RateRatio returns Factor.
+func (p *Interpolator) RateRatio() (up, down int) {
+	return p.Factor, 1
+}
+
+// Skip returns the outputs lost with n dropped input samples. Every input
+// produces Factor outputs, so the phase is unchanged.
+func (p *Interpolator) Skip(n int) int {
+	return n * p.Factor
+}
+
+// Reset clears the filter history.
+func (p *Interpolator) Reset() {
+	for i := range p.history {
+		p.history[i] = 0
+	}
+}
+
+// HalfBandCascade changes the sample rate by a power of two with a chain of
+// half-band stages, each decimating or interpolating by two. Each stage runs
+// at the lowest rate it can, which is far cheaper than a single filter at the
+// full rate.
+type HalfBandCascade struct {
+	stages []Processor
+	factor int
+	up     bool
+}
+
+// NewHalfBandDecimator creates a cascade decimating by 2^stages, using
+// half-band filters of the given number of taps.
+func NewHalfBandDecimator(stages, taps int) *HalfBandCascade {
+	h := HalfBand(taps)
+	c := &HalfBandCascade{factor: 1 << uint(stages)}
+	for i := 0; i < stages; i++ {
+		c.stages = append(c.stages, NewDecimatorFIR(2, h))
+	}
+	return c
+}
+
+// NewHalfBandInterpolator creates a cascade interpolating by 2^stages, using
+// half-band filters of the given number of taps.
+func NewHalfBandInterpolator(stages, taps int) *HalfBandCascade {
+	h := HalfBand(taps)
+	c := &HalfBandCascade{factor: 1 << uint(stages), up: true}
+	for i := 0; i < stages; i++ {
+		c.stages = append(c.stages, NewInterpolatorFIR(2, h))
+	}
+	return c
+}
+
+// Factor returns the overall rate change factor.
+func (c *HalfBandCascade) Factor() int {
+	return c.factor
+}
+
+// RateRatio returns the factor for an interpolating cascade and its
+// reciprocal for a decimating one.
+func (c *HalfBandCascade) RateRatio() (up, down int) {
+	if c.up {
+		return c.factor, 1
+	}
+	return 1, c.factor
+}
+
+// Process runs a block of samples through every stage.
+func (c *HalfBandCascade) Process(X []float64) []float64 {
+	for _, stage := range c.stages {
+		X = stage.Process(X)
+	}
+	return X
+}
+
+// Skip passes the dropped samples through every stage.
+func (c *HalfBandCascade) Skip(n int) int {
+	return skipStages(c.stages, n)
+}
+
+// Reset clears the state of every stage.
+func (c *HalfBandCascade) Reset() {
+	for _, stage := range c.stages {
+		stage.Reset()
+	}
+}
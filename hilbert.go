@@ -0,0 +1,90 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Smoothing selects how an estimated track is smoothed.
+type Smoothing int
+
+const (
+	// NoSmoothing leaves the track unchanged.
+	NoSmoothing Smoothing = iota
+
+	// MovingAverageSmoothing applies a centered moving average.
+	MovingAverageSmoothing
+
+	// MedianSmoothing applies a centered running median, which rejects the
+	// spikes that appear where the amplitude passes near zero.
+	MedianSmoothing
+)
+
+// AnalyticSignal returns the analytic signal x + jH{x} of the data set, where
+// H is the Hilbert transform, computed by zeroing the negative frequencies.
+func (d DataSet) AnalyticSignal() IQ {
+	n := NextPow2(len(d))
+	X := realToComplex(d, n)
+	fft(X, false)
+	for k := 1; k < n; k++ {
+		switch {
+		case k < n/2:
+			X[k] *= 2
+		case k > n/2:
+			X[k] = 0
+		}
+	}
+	fft(X, true)
+	return IQ(X[:len(d)])
+}
+
+// Envelope returns the magnitude of the analytic signal.
+func (d DataSet) Envelope() DataSet {
+	return d.AnalyticSignal().Magnitude()
+}
+
+// Phase returns the unwrapped phase of each sample in radians.
+func (s IQ) Phase() DataSet {
+	phase := make([]float64, len(s))
+	for i, v := range s {
+		phase[i] = cmplx.Phase(v)
+	}
+	return Unwrap(phase)
+}
+
+// Unwrap removes the 2*pi jumps from a phase track.
+func Unwrap(phase DataSet) DataSet {
+	out := make([]float64, len(phase))
+	var offset float64
+	for i := 0; i < len(phase); i++ {
+		if i > 0 {
+			delta := phase[i] - phase[i-1]
+			offset -= 2 * math.Pi * math.Round(delta/(2*math.Pi))
+		}
+		out[i] = phase[i] + offset
+	}
+	return out
+}
+
+// InstantaneousFrequency returns the instantaneous frequency in Hz of each
+// sample from the phase derivative of the analytic signal. The derivative is
+// taken as the angle between successive samples, so no unwrapping is needed,
+// and the track is then smoothed over width samples.
+func (d DataSet) InstantaneousFrequency(fS float64, smoothing Smoothing, width int) DataSet {
+	z := d.AnalyticSignal()
+	freq := make(DataSet, len(z))
+	for i := 1; i < len(z); i++ {
+		freq[i] = cmplx.Phase(z[i]*cmplx.Conj(z[i-1])) * fS / (2 * math.Pi)
+	}
+	if len(freq) > 1 {
+		freq[0] = freq[1]
+	}
+
+	switch smoothing {
+	case MovingAverageSmoothing:
+		return freq.MovingAverage(width)
+	case MedianSmoothing:
+		return freq.MedianFilter(width)
+	}
+	return freq
+}
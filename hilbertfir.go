@@ -0,0 +1,80 @@
+package dsp
+
+import "math"
+
+// HilbertFIR designs a linear phase FIR Hilbert transformer, which shifts the
+// phase of every frequency by -90 degrees, delayed by (taps-1)/2 samples. The
+// ideal response is windowed with a Blackman window. The number of taps is
+// rounded up to the form 4k + 3 so the outermost taps are nonzero; every
+// other tap is zero. The response falls off near DC and Nyquist, more
+// steeply the fewer taps are used.
+func HilbertFIR(taps int) DataSet {
+	if taps < 3 {
+		taps = 3
+	}
+	for taps%4 != 3 {
+		taps++
+	}
+	w := Symmetric(Blackman, taps)
+	h := make([]float64, taps)
+	center := (taps - 1) / 2
+	for i := range h {
+		t := i - center
+		if t%2 != 0 {
+			h[i] = 2 / (math.Pi * float64(t)) * w[i]
+		}
+	}
+	return h
+}
+
+// AnalyticFilter generates the analytic signal of a stream block by block
+// with an FIR Hilbert transformer. The in-phase output is the input delayed
+// to match the transformer, so the output lags the input by Delay samples.
+type AnalyticFilter struct {
+	Taps DataSet
+
+	history []float64
+}
+
+// NewAnalyticFilter creates a streaming analytic signal generator using a
+// Hilbert transformer of the given number of taps.
+func NewAnalyticFilter(taps int) *AnalyticFilter {
+	h := HilbertFIR(taps)
+	return &AnalyticFilter{Taps: h, history: make([]float64, len(h)-1)}
+}
+
+// Delay returns the latency of the filter in samples.
+func (a *AnalyticFilter) Delay() int {
+	return (len(a.Taps) - 1) / 2
+}
+
+// Process returns the analytic signal of a block of samples, continuing from
+// the state left by the previous block.
+func (a *AnalyticFilter) Process(X []float64) IQ {
+	h := len(a.history)
+	buf := make([]float64, h+len(X))
+	copy(buf, a.history)
+	copy(buf[h:], X)
+
+	delay := a.Delay()
+	out := make(IQ, len(X))
+	for i := range X {
+		var q float64
+		// only the odd offsets from the center are nonzero
+		for k := 0; k < len(a.Taps); k++ {
+			if c := a.Taps[k]; c != 0 {
+				q += c * buf[h+i-k]
+			}
+		}
+		out[i] = complex(buf[h+i-delay], q)
+	}
+	copy(a.history, buf[len(buf)-h:])
+	return out
+}
+
+// Reset clears the filter history.
+func (a *AnalyticFilter) Reset() {
+	for i := range a.history {
+		a.history[i] = 0
+	}
+}
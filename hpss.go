@@ -0,0 +1,78 @@
+package dsp
+
+import "sort"
+
+// HPSS separates the data set into harmonic and percussive components by
+// median filtering its magnitude spectrogram along time (which keeps steady
+// tones) and along frequency (which keeps broadband transients). The kernel
+// is the median filter length in frames and bins. It returns the harmonic and
+// percussive signals, which sum to the original.
+func (d DataSet) HPSS(frameSize, hop, kernel int) (DataSet, DataSet) {
+	window := Hann(frameSize)
+	frames := stft(d, frameSize, hop, window)
+	if len(frames) == 0 {
+		return DataSet{}, DataSet{}
+	}
+	bins := len(frames[0])
+
+	mag := make([][]float64, len(frames))
+	for t, frame := range frames {
+		mag[t] = Magnitude(frame)
+	}
+
+	// median along time for each bin
+	harmonic := make([][]float64, len(frames))
+	for t := range harmonic {
+		harmonic[t] = make([]float64, bins)
+	}
+	track := make([]float64, len(frames))
+	for k := 0; k < bins; k++ {
+		for t := range frames {
+			track[t] = mag[t][k]
+		}
+		filtered := medianFilter(track, kernel)
+		for t := range frames {
+			harmonic[t][k] = filtered[t]
+		}
+	}
+
+	hFrames := make([][]complex128, len(frames))
+	pFrames := make([][]complex128, len(frames))
+	for t, frame := range frames {
+		percussive := medianFilter(mag[t], kernel)
+		hFrames[t] = make([]complex128, bins)
+		pFrames[t] = make([]complex128, bins)
+		for k, v := range frame {
+			h2 := harmonic[t][k] * harmonic[t][k]
+			p2 := percussive[k] * percussive[k]
+			mask := 0.5
+			if h2+p2 > 0 {
+				mask = h2 / (h2 + p2)
+			}
+			hFrames[t][k] = v * complex(mask, 0)
+			pFrames[t][k] = v - hFrames[t][k]
+		}
+	}
+	return istft(hFrames, frameSize, hop, window, len(d)), istft(pFrames, frameSize, hop, window, len(d))
+}
+
+// medianFilter returns the centered running median over a window of k
+// samples, truncated at the edges.
+func medianFilter(x []float64, k int) []float64 {
+	out := make([]float64, len(x))
+	half := k / 2
+	buf := make([]float64, 0, k)
+	for i := range x {
+		start := maxInt(0, i-half)
+		stop := minInt(len(x), i-half+k)
+		buf = append(buf[:0], x[start:stop]...)
+		sort.Float64s(buf)
+		m := len(buf) / 2
+		if len(buf)%2 == 0 {
+			out[i] = (buf[m-1] + buf[m]) / 2
+		} else {
+			out[i] = buf[m]
+		}
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+)
+
+// zpk is an analog filter given by its zeros, poles and gain in the s-plane.
+type zpk struct {
+	z, p []complex128
+	k    float64
+}
+
+// butterworth returns the analog Butterworth prototype with its cutoff at 1
+// rad/s.
+func butterworth(order int) zpk {
+	p := make([]complex128, order)
+	for i := range p {
+		theta := math.Pi * float64(2*i+order+1) / float64(2*order)
+		p[i] = cmplx.Rect(1, theta)
+	}
+	return zpk{p: p, k: 1}
+}
+
+// chebyshev1 returns the analog Chebyshev type I prototype with ripple dB of
+// passband ripple, ending at 1 rad/s.
+func chebyshev1(order int, ripple float64) zpk {
+	eps := math.Sqrt(math.Pow(10, ripple/10) - 1)
+	mu := math.Asinh(1/eps) / float64(order)
+	p := make([]complex128, order)
+	prod := complex(1, 0)
+	for i := range p {
+		theta := math.Pi * float64(2*i+1) / float64(2*order)
+		p[i] = complex(-math.Sinh(mu)*math.Sin(theta), math.Cosh(mu)*math.Cos(theta))
+		prod *= -p[i]
+	}
+	k := real(prod)
+	if order%2 == 0 {
+		// even orders start at the bottom of the ripple
+		k /= math.Sqrt(1 + eps*eps)
+	}
+	return zpk{p: p, k: k}
+}
+
+// chebyshev2 returns the analog Chebyshev type II prototype with its stopband,
+// attenuated by attenuation dB, starting at 1 rad/s.
+func chebyshev2(order int, attenuation float64) zpk {
+	eps := 1 / math.Sqrt(math.Pow(10, attenuation/10)-1)
+	mu := math.Asinh(1/eps) / float64(order)
+	var z []complex128
+	p := make([]complex128, order)
+	for i := range p {
+		theta := math.Pi * float64(2*i+1) / float64(2*order)
+		if 2*i+1 != order {
+			// the middle zero of an odd order lies at infinity
+			z = append(z, complex(0, 1/math.Cos(theta)))
+		}
+		p[i] = 1 / complex(-math.Sinh(mu)*math.Sin(theta), math.Cosh(mu)*math.Cos(theta))
+	}
+	return zpk{z: z, p: p, k: real(rootProduct(p) / rootProduct(z))}
+}
+
+// bessel returns the analog Bessel prototype with its -3 dB point at 1 rad/s.
+// The poles are the roots of the reverse Bessel polynomial, which gives unit
+// group delay at DC, scaled to move the -3 dB point.
+func bessel(order int) zpk {
+	// coefficients from the highest power down
+	poly := make([]float64, order+1)
+	for k := 0; k <= order; k++ {
+		c := 1.0
+		for i := order - k + 1; i <= 2*order-k; i++ {
+			c *= float64(i)
+		}
+		for i := 1; i <= k; i++ {
+			c /= float64(i)
+		}
+		poly[order-k] = c / math.Pow(2, float64(order-k))
+	}
+	p := polyRoots(poly)
+
+	// the magnitude falls monotonically, so bisect for the -3 dB point
+	gain := func(w float64) float64 {
+		v, _ := polyEval(poly, complex(0, w))
+		return poly[order] / cmplx.Abs(v)
+	}
+	lo, hi := 0.0, 1.0
+	for gain(hi) > math.Sqrt2/2 {
+		hi *= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if gain(mid) > math.Sqrt2/2 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	for i := range p {
+		p[i] /= complex(lo, 0)
+	}
+	return zpk{p: p, k: real(rootProduct(p))}
+}
+
+// digital transforms a normalized analog low-pass prototype into a digital
+// filter of the given type, with its band edges prewarped so that they land
+// exactly where asked after the bilinear transform. For band types fC is the
+// center and bw the width of the band; for the others bw is unused.
+func (a zpk) digital(name string, fC, bw, fS float64, kind FilterType) SOS {
+	fs2 := 2 * fS
+	warp := func(f float64) float64 {
+		return fs2 * math.Tan(math.Pi*f/fS)
+	}
+
+	switch kind {
+	case LowPassFilter:
+		a = a.lowPass(warp(fC))
+	case HighPassFilter:
+		a = a.highPass(warp(fC))
+	case BandPassFilter, BandStopFilter:
+		lo, hi := fC-bw/2, fC+bw/2
+		if bw <= 0 || lo <= 0 || hi >= fS/2 {
+			log.Fatalf("%s requires a band between 0 and %v, got %v to %v", name, fS/2, lo, hi)
+		}
+		w1, w2 := warp(lo), warp(hi)
+		if kind == BandPassFilter {
+			a = a.bandPass(math.Sqrt(w1*w2), w2-w1)
+		} else {
+			a = a.bandStop(math.Sqrt(w1*w2), w2-w1)
+		}
+	default:
+		log.Fatalf("%s does not support filter type %d", name, kind)
+	}
+	return a.bilinear(fs2)
+}
+
+// checkEdgeKind stops a low-pass or high-pass constructor being asked for a
+// band type, which needs the band width that only its band counterpart takes.
+func checkEdgeKind(name, band string, kind FilterType) {
+	if kind == BandPassFilter || kind == BandStopFilter {
+		log.Fatalf("%s designs low-pass or high-pass filters, use %s for band-pass and band-stop", name, band)
+	}
+}
+
+// lowPass moves the cutoff of a prototype to wo.
+func (a zpk) lowPass(wo float64) zpk {
+	out := zpk{k: a.k * math.Pow(wo, float64(len(a.p)-len(a.z)))}
+	for _, z := range a.z {
+		out.z = append(out.z, z*complex(wo, 0))
+	}
+	for _, p := range a.p {
+		out.p = append(out.p, p*complex(wo, 0))
+	}
+	return out
+}
+
+// highPass turns a prototype into a high-pass filter with cutoff wo.
+func (a zpk) highPass(wo float64) zpk {
+	out := zpk{k: a.k * real(rootProduct(a.z)/rootProduct(a.p))}
+	for _, z := range a.z {
+		out.z = append(out.z, complex(wo, 0)/z)
+	}
+	for _, p := range a.p {
+		out.p = append(out.p, complex(wo, 0)/p)
+	}
+	for i := len(a.z); i < len(a.p); i++ {
+		out.z = append(out.z, 0)
+	}
+	return out
+}
+
+// bandPass turns a prototype into a band-pass filter centered on wo with
+// bandwidth bw.
+func (a zpk) bandPass(wo, bw float64) zpk {
+	out := zpk{k: a.k * math.Pow(bw, float64(len(a.p)-len(a.z)))}
+	split := func(r complex128) (complex128, complex128) {
+		h := r * complex(bw/2, 0)
+		d := cmplx.Sqrt(h*h - complex(wo*wo, 0))
+		return h + d, h - d
+	}
+	for _, z := range a.z {
+		z1, z2 := split(z)
+		out.z = append(out.z, z1, z2)
+	}
+	for _, p := range a.p {
+		p1, p2 := split(p)
+		out.p = append(out.p, p1, p2)
+	}
+	for i := len(a.z); i < len(a.p); i++ {
+		out.z = append(out.z, 0)
+	}
+	return out
+}
+
+// bandStop turns a prototype into a band-stop filter centered on wo with
+// bandwidth bw.
+func (a zpk) bandStop(wo, bw float64) zpk {
+	out := zpk{k: a.k * real(rootProduct(a.z)/rootProduct(a.p))}
+	split := func(r complex128) (complex128, complex128) {
+		h := complex(bw/2, 0) / r
+		d := cmplx.Sqrt(h*h - complex(wo*wo, 0))
+		return h + d, h - d
+	}
+	for _, z := range a.z {
+		z1, z2 := split(z)
+		out.z = append(out.z, z1, z2)
+	}
+	for _, p := range a.p {
+		p1, p2 := split(p)
+		out.p = append(out.p, p1, p2)
+	}
+	for i := len(a.z); i < len(a.p); i++ {
+		out.z = append(out.z, complex(0, wo), complex(0, -wo))
+	}
+	return out
+}
+
+// bilinear maps the analog filter to the z-plane with the bilinear transform
+// s = fs2 (z - 1) / (z + 1), where fs2 is twice the sample rate.
+func (a zpk) bilinear(fs2 float64) SOS {
+	f := complex(fs2, 0)
+	var zeros, poles []complex128
+	num, den := complex(1, 0), complex(1, 0)
+	for _, z := range a.z {
+		zeros = append(zeros, (f+z)/(f-z))
+		num *= f - z
+	}
+	for _, p := range a.p {
+		poles = append(poles, (f+p)/(f-p))
+		den *= f - p
+	}
+	for i := len(a.z); i < len(a.p); i++ {
+		zeros = append(zeros, -1)
+	}
+	return FilterFromPZK(zeros, poles, a.k*real(num/den))
+}
+
+// rootProduct returns the product of the negated roots, which is the value at
+// s = 0 of the monic polynomial with those roots.
+func rootProduct(roots []complex128) complex128 {
+	prod := complex(1, 0)
+	for _, r := range roots {
+		prod *= -r
+	}
+	return prod
+}
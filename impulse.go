@@ -0,0 +1,124 @@
+package dsp
+
+import "math"
+
+// IRNormalization selects how an impulse response is scaled.
+type IRNormalization int
+
+const (
+	// PeakNormalization scales the largest absolute sample to 1.
+	PeakNormalization IRNormalization = iota
+
+	// EnergyNormalization scales the sum of squares to 1.
+	EnergyNormalization
+
+	// DCNormalization scales the sum of the samples, the gain at 0 Hz, to 1.
+	DCNormalization
+)
+
+// IRReport describes how much of an impulse response survived windowing or
+// truncation.
+type IRReport struct {
+	// Start is the index in the original response of the first kept sample.
+	Start int
+
+	// Length is the number of samples kept.
+	Length int
+
+	// Energy is the sum of squares of the original response.
+	Energy float64
+
+	// Retained is the sum of squares of the windowed response.
+	Retained float64
+}
+
+// RetainedDB returns the windowed energy relative to the original in dB.
+func (r IRReport) RetainedDB() float64 {
+	return 10 * math.Log10(r.Retained/r.Energy)
+}
+
+// IRPeak returns the index of the largest absolute sample.
+func (d DataSet) IRPeak() int {
+	peak := 0
+	for i, v := range d {
+		if math.Abs(v) > math.Abs(d[peak]) {
+			peak = i
+		}
+	}
+	return peak
+}
+
+// TruncateIR keeps the first length samples of an impulse response, fading
+// the last fade of them out with a half Hann window so the kernel does not
+// end abruptly.
+func (d DataSet) TruncateIR(length, fade int) (DataSet, IRReport) {
+	return d.cutIR(0, length, 0, fade)
+}
+
+// WindowIR keeps pre samples before and post samples after the peak of an
+// impulse response, fading fade samples in at the start and out at the end
+// with half Hann windows. This removes the noise before the direct sound and
+// the noise floor after the decay.
+func (d DataSet) WindowIR(pre, post, fade int) (DataSet, IRReport) {
+	peak := d.IRPeak()
+	start := maxInt(0, peak-pre)
+	fadeIn := minInt(fade, peak-start)
+	return d.cutIR(start, peak+post+1-start, fadeIn, fade)
+}
+
+// NormalizeIR scales an impulse response.
+func (d DataSet) NormalizeIR(norm IRNormalization) DataSet {
+	var scale float64
+	switch norm {
+	case PeakNormalization:
+		if len(d) > 0 {
+			scale = math.Abs(d[d.IRPeak()])
+		}
+	case EnergyNormalization:
+		var sum float64
+		for _, v := range d {
+			sum += v * v
+		}
+		scale = math.Sqrt(sum)
+	case DCNormalization:
+		scale = math.Abs(d.Sum())
+	}
+
+	values := make([]float64, len(d))
+	copy(values, d)
+	if scale != 0 {
+		for i := range values {
+			values[i] /= scale
+		}
+	}
+	return values
+}
+
+// cutIR returns length samples from start with the given fades applied, and
+// a report of the energy kept.
+func (d DataSet) cutIR(start, length, fadeIn, fadeOut int) (DataSet, IRReport) {
+	end := minInt(len(d), start+length)
+	if start > end {
+		start = end
+	}
+	values := make([]float64, end-start)
+	copy(values, d[start:end])
+
+	fadeIn = minInt(fadeIn, len(values))
+	for i := 0; i < fadeIn; i++ {
+		values[i] *= 0.5 - 0.5*math.Cos(math.Pi*float64(i)/float64(fadeIn))
+	}
+	fadeOut = minInt(fadeOut, len(values))
+	for i := 0; i < fadeOut; i++ {
+		values[len(values)-1-i] *= 0.5 - 0.5*math.Cos(math.Pi*float64(i+1)/float64(fadeOut+1))
+	}
+
+	report := IRReport{Start: start, Length: len(values)}
+	for _, v := range d {
+		report.Energy += v * v
+	}
+	for _, v := range values {
+		report.Retained += v * v
+	}
+	return values, report
+}
@@ -0,0 +1,66 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+)
+
+// cubicSpline returns a natural cubic spline interpolant through the points
+// (xs[i], ys[i]). The xs must be strictly increasing.
+func cubicSpline(xs, ys []float64) func(float64) float64 {
+	n := len(xs)
+	if n == 1 {
+		return func(float64) float64 { return ys[0] }
+	}
+
+	// solve the tridiagonal system for the second derivatives
+	m := make([]float64, n)
+	if n > 2 {
+		c := make([]float64, n)
+		d := make([]float64, n)
+		for i := 1; i < n-1; i++ {
+			h0 := xs[i] - xs[i-1]
+			h1 := xs[i+1] - xs[i]
+			a := h0
+			b := 2 * (h0 + h1)
+			r := 6 * ((ys[i+1]-ys[i])/h1 - (ys[i]-ys[i-1])/h0)
+			if i > 1 {
+				b -= a * c[i-1]
+				r -= a * d[i-1]
+			}
+			c[i] = h1 / b
+			d[i] = r / b
+		}
+		for i := n - 2; i >= 1; i-- {
+			m[i] = d[i] - c[i]*m[i+1]
+		}
+	}
+
+	return func(x float64) float64 {
+		i := sort.SearchFloat64s(xs, x) - 1
+		if i < 0 {
+			i = 0
+		}
+		if i > n-2 {
+			i = n - 2
+		}
+		h := xs[i+1] - xs[i]
+		a := (xs[i+1] - x) / h
+		b := (x - xs[i]) / h
+		return a*ys[i] + b*ys[i+1] + ((a*a*a-a)*m[i]+(b*b*b-b)*m[i+1])*h*h/6
+	}
+}
+
+// sampleAt returns the value of x at the fractional index t by linear
+// interpolation. Indices outside the data read as zero.
+func sampleAt(x []float64, t float64) float64 {
+	i := int(math.Floor(t))
+	if i < 0 || i >= len(x) {
+		return 0
+	}
+	frac := t - float64(i)
+	if i == len(x)-1 || frac == 0 {
+		return x[i] * (1 - frac)
+	}
+	return x[i]*(1-frac) + x[i+1]*frac
+}
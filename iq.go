@@ -0,0 +1,45 @@
+package dsp
+
+// IQ is a complex baseband signal with in-phase and quadrature components.
+type IQ []complex128
+
+// NewIQ creates an IQ signal from separate in-phase and quadrature data sets.
+func NewIQ(I, Q DataSet) IQ {
+	n := len(I)
+	if len(Q) < n {
+		n = len(Q)
+	}
+	iq := make([]complex128, n)
+	for i := 0; i < n; i++ {
+		iq[i] = complex(I[i], Q[i])
+	}
+	return iq
+}
+
+// Len returns the number of samples.
+func (s IQ) Len() int {
+	return len(s)
+}
+
+// I returns the in-phase component.
+func (s IQ) I() DataSet {
+	values := make([]float64, len(s))
+	for i, v := range s {
+		values[i] = real(v)
+	}
+	return values
+}
+
+// Q returns the quadrature component.
+func (s IQ) Q() DataSet {
+	values := make([]float64, len(s))
+	for i, v := range s {
+		values[i] = imag(v)
+	}
+	return values
+}
+
+// Magnitude returns the magnitude of each sample.
+func (s IQ) Magnitude() DataSet {
+	return Magnitude(s)
+}
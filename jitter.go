@@ -0,0 +1,101 @@
+package dsp
+
+import "math"
+
+// Jitter summarizes the timing jitter of a clock from its edge times. Times
+// are in the units of the edges, usually seconds.
+type Jitter struct {
+	// Period is the period of the ideal clock fitted to the edges.
+	Period float64
+
+	// TIE holds the time interval error of each edge, its offset from the
+	// ideal clock.
+	TIE DataSet
+
+	// RMS and PeakToPeak are the spread of the TIE.
+	RMS        float64
+	PeakToPeak float64
+
+	// PeriodJitter is the RMS deviation of each period from the mean.
+	PeriodJitter float64
+
+	// CycleToCycle is the RMS difference between successive periods.
+	CycleToCycle float64
+
+	// RJ is the RMS of the random, Gaussian, jitter and DJ the peak to peak
+	// deterministic jitter, separated with a dual-Dirac model.
+	RJ float64
+	DJ float64
+}
+
+// TIE returns the time interval error of each edge: its offset from the ideal
+// clock with edges at t0 + i T fitted to them by least squares, together with
+// the fitted period T. Every clock edge must be present.
+func TIE(edges DataSet) (DataSet, float64) {
+	n := float64(len(edges))
+	if len(edges) < 2 {
+		return make(DataSet, len(edges)), 0
+	}
+	var si, st, sii, sit float64
+	for i, t := range edges {
+		x := float64(i)
+		si += x
+		st += t
+		sii += x * x
+		sit += x * t
+	}
+	period := (n*sit - si*st) / (n*sii - si*si)
+	t0 := (st - period*si) / n
+
+	tie := make(DataSet, len(edges))
+	for i, t := range edges {
+		tie[i] = t - t0 - period*float64(i)
+	}
+	return tie, period
+}
+
+// AnalyzeJitter measures the jitter of a clock from its edge times, such as
+// the rising edges of a PulseTrain. The TIE distribution is split into random
+// and deterministic parts by matching its second and fourth moments to a
+// Gaussian convolved with two Diracs DJ apart. Bounded jitter flattens the
+// distribution, so only a TIE with negative excess kurtosis shows any DJ.
+func AnalyzeJitter(edges DataSet) *Jitter {
+	tie, period := TIE(edges)
+	j := &Jitter{Period: period, TIE: tie}
+	if len(tie) < 2 {
+		return j
+	}
+	j.RMS = tie.Stdev()
+	j.PeakToPeak = tie.Max() - tie.Min()
+
+	periods := make(DataSet, len(edges)-1)
+	for i := range periods {
+		periods[i] = edges[i+1] - edges[i]
+	}
+	j.PeriodJitter = periods.Stdev()
+	var ssq float64
+	for i := 1; i < len(periods); i++ {
+		d := periods[i] - periods[i-1]
+		ssq += d * d
+	}
+	if len(periods) > 1 {
+		j.CycleToCycle = math.Sqrt(ssq / float64(len(periods)-1))
+	}
+
+	mean := tie.Mean()
+	var m2, m4 float64
+	for _, v := range tie {
+		d := (v - mean) * (v - mean)
+		m2 += d
+		m4 += d * d
+	}
+	m2 /= float64(len(tie))
+	m4 /= float64(len(tie))
+	var a float64
+	if excess := 3*m2*m2 - m4; excess > 0 {
+		a = math.Min(math.Sqrt(excess/2), m2)
+	}
+	j.RJ = math.Sqrt(m2 - a)
+	j.DJ = 2 * math.Sqrt(a)
+	return j
+}
@@ -0,0 +1,212 @@
+package dsp
+
+import (
+	"log"
+	"math"
+)
+
+// Lattice is an FIR lattice filter, the structure of a linear prediction
+// error filter. Each stage m updates the forward and backward errors
+//
+//	f_m[n] = f_(m-1)[n] + K_m b_(m-1)[n-1]
+//	b_m[n] = K_m f_(m-1)[n] + b_(m-1)[n-1]
+//
+// and the output is Gain times the final forward error. The filter is
+// minimum phase whenever every |K_m| < 1.
+type Lattice struct {
+	K    DataSet
+	Gain float64
+
+	b []float64
+}
+
+// NewLattice creates an FIR lattice filter from its reflection coefficients.
+func NewLattice(k DataSet, gain float64) *Lattice {
+	return &Lattice{K: k, Gain: gain}
+}
+
+// Lattice converts an FIR filter to lattice form. The filter must have no
+// feedback, and no step of the conversion may give a reflection coefficient
+// of magnitude exactly 1, as happens with zeros on the unit circle. Filters
+// that are not minimum phase have some |K_m| > 1.
+func (f Filter) Lattice() *Lattice {
+	num, den := normalizeTF(f)
+	for _, v := range den[1:] {
+		if v != 0 {
+			log.Fatal("Lattice requires an FIR filter")
+		}
+	}
+	if num[0] == 0 {
+		log.Fatal("Lattice requires a numerator with a nonzero first coefficient")
+	}
+	a := make([]float64, len(num))
+	for i, v := range num {
+		a[i] = v / num[0]
+	}
+	k, _ := stepDown("Lattice", a)
+	return NewLattice(k, num[0])
+}
+
+// Filter converts the lattice back to a direct form FIR filter.
+func (l *Lattice) Filter() *Filter {
+	a := []float64{1}
+	for _, k := range l.K {
+		a = stepUp(a, k)
+	}
+	den := make([]float64, len(a))
+	den[0] = 1
+	for i := range a {
+		a[i] *= l.Gain
+	}
+	return &Filter{B: den, A: a}
+}
+
+// Process filters a block of samples, continuing from the state left by the
+// previous block.
+func (l *Lattice) Process(X []float64) []float64 {
+	M := len(l.K)
+	if len(l.b) != M {
+		l.b = make([]float64, M)
+	}
+	Y := make([]float64, len(X))
+	for n, x := range X {
+		f, b := x, x
+		for m, k := range l.K {
+			// l.b[m] holds b_m[n-1]
+			prev := l.b[m]
+			l.b[m] = b
+			f, b = f+k*prev, k*f+prev
+		}
+		Y[n] = l.Gain * f
+	}
+	return Y
+}
+
+// Reset clears the filter state.
+func (l *Lattice) Reset() {
+	for i := range l.b {
+		l.b[i] = 0
+	}
+}
+
+// LatticeLadder is an IIR lattice-ladder (Gray-Markel) filter. The lattice
+// with reflection coefficients K realizes the poles, and the output is a
+// weighted sum of the backward errors of each stage with ladder coefficients
+// V, which realizes the zeros. It stays stable under coefficient quantization
+// as long as every |K_m| < 1, which makes it a common choice for adaptive and
+// speech synthesis filters.
+type LatticeLadder struct {
+	K, V DataSet
+
+	b []float64
+}
+
+// NewLatticeLadder creates a lattice-ladder filter from its reflection and
+// ladder coefficients. V must have one more element than K. An all-pole
+// filter, such as the synthesis filter of an ARModel, has V = [g, 0, ...].
+func NewLatticeLadder(k, v DataSet) *LatticeLadder {
+	if len(v) != len(k)+1 {
+		log.Fatal("NewLatticeLadder requires one more ladder than reflection coefficient")
+	}
+	return &LatticeLadder{K: k, V: v}
+}
+
+// LatticeLadder converts the filter to lattice-ladder form. The filter must
+// be stable.
+func (f Filter) LatticeLadder() *LatticeLadder {
+	num, den := normalizeTF(f)
+	k, polys := stepDown("LatticeLadder", den)
+	for _, km := range k {
+		if math.Abs(km) >= 1 {
+			log.Fatalf("LatticeLadder requires a stable filter, got reflection coefficient %v", km)
+		}
+	}
+
+	// express the numerator as a sum of the reversed prediction polynomials
+	c := make([]float64, len(num))
+	copy(c, num)
+	v := make([]float64, len(num))
+	for m := len(polys) - 1; m >= 0; m-- {
+		a := polys[m]
+		v[m] = c[m]
+		for i := 0; i <= m; i++ {
+			c[i] -= v[m] * a[m-i]
+		}
+	}
+	return NewLatticeLadder(k, v)
+}
+
+// Filter converts the lattice-ladder back to a direct form filter.
+func (l *LatticeLadder) Filter() *Filter {
+	polys := [][]float64{{1}}
+	for _, k := range l.K {
+		polys = append(polys, stepUp(polys[len(polys)-1], k))
+	}
+	M := len(l.K)
+	num := make([]float64, M+1)
+	for m, a := range polys {
+		for i := 0; i <= m; i++ {
+			num[i] += l.V[m] * a[m-i]
+		}
+	}
+	return &Filter{B: polys[M], A: num}
+}
+
+// Process filters a block of samples, continuing from the state left by the
+// previous block.
+func (l *LatticeLadder) Process(X []float64) []float64 {
+	M := len(l.K)
+	if len(l.b) != M+1 {
+		l.b = make([]float64, M+1)
+	}
+	Y := make([]float64, len(X))
+	for n, x := range X {
+		// run the forward error down from stage M, using the backward
+		// errors of the previous sample held in l.b
+		f := x
+		for m := M; m >= 1; m-- {
+			f -= l.K[m-1] * l.b[m-1]
+			l.b[m] = l.K[m-1]*f + l.b[m-1]
+		}
+		l.b[0] = f
+
+		var y float64
+		for m, v := range l.V {
+			y += v * l.b[m]
+		}
+		Y[n] = y
+	}
+	return Y
+}
+
+// Reset clears the filter state.
+func (l *LatticeLadder) Reset() {
+	for i := range l.b {
+		l.b[i] = 0
+	}
+}
+
+// stepDown runs the Levinson recursion backwards on a monic polynomial. It
+// returns the reflection coefficients and the prediction polynomial of every
+// order from 0 up to that of a. A reflection coefficient of magnitude 1 ends
+// the recursion, which exits naming the caller.
+func stepDown(name string, a []float64) (DataSet, [][]float64) {
+	M := len(a) - 1
+	k := make([]float64, M)
+	polys := make([][]float64, M+1)
+	polys[M] = a
+	for m := M; m >= 1; m-- {
+		am := polys[m]
+		km := am[m]
+		if math.Abs(km) == 1 {
+			log.Fatalf("%s requires no reflection coefficient of magnitude 1, got %v", name, km)
+		}
+		k[m-1] = km
+		prev := make([]float64, m)
+		for i := 0; i < m; i++ {
+			prev[i] = (am[i] - km*am[m-i]) / (1 - km*km)
+		}
+		polys[m-1] = prev
+	}
+	return k, polys
+}
@@ -0,0 +1,306 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// covariance returns the sample covariance matrix (1/K) sum s s^H of the
+// snapshot vectors.
+func covariance(snapshots [][]complex128) [][]complex128 {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	m := len(snapshots[0])
+	R := make([][]complex128, m)
+	for i := range R {
+		R[i] = make([]complex128, m)
+	}
+	for _, s := range snapshots {
+		for i := 0; i < m; i++ {
+			for j := 0; j < m; j++ {
+				R[i][j] += s[i] * cmplx.Conj(s[j])
+			}
+		}
+	}
+	scale := complex(1/float64(len(snapshots)), 0)
+	for i := range R {
+		for j := range R[i] {
+			R[i][j] *= scale
+		}
+	}
+	return R
+}
+
+// cmatInverse inverts a square complex matrix with Gauss-Jordan elimination
+// and partial pivoting. It returns nil if the matrix is singular.
+func cmatInverse(A [][]complex128) [][]complex128 {
+	n := len(A)
+	aug := make([][]complex128, n)
+	for i := range A {
+		aug[i] = make([]complex128, 2*n)
+		copy(aug[i], A[i])
+		aug[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if cmplx.Abs(aug[r][col]) > cmplx.Abs(aug[pivot][col]) {
+				pivot = r
+			}
+		}
+		if aug[pivot][col] == 0 {
+			return nil
+		}
+		aug[col], aug[pivot] = aug[pivot], aug[col]
+		p := aug[col][col]
+		for j := range aug[col] {
+			aug[col][j] /= p
+		}
+		for r := 0; r < n; r++ {
+			if r == col || aug[r][col] == 0 {
+				continue
+			}
+			f := aug[r][col]
+			for j := range aug[r] {
+				aug[r][j] -= f * aug[col][j]
+			}
+		}
+	}
+	inv := make([][]complex128, n)
+	for i := range aug {
+		inv[i] = aug[i][n:]
+	}
+	return inv
+}
+
+// cmatVec returns the matrix-vector product A v.
+func cmatVec(A [][]complex128, v []complex128) []complex128 {
+	out := make([]complex128, len(A))
+	for i, row := range A {
+		for j, a := range row {
+			out[i] += a * v[j]
+		}
+	}
+	return out
+}
+
+// cdot returns the inner product a^H b.
+func cdot(a, b []complex128) complex128 {
+	var sum complex128
+	for i := range a {
+		sum += cmplx.Conj(a[i]) * b[i]
+	}
+	return sum
+}
+
+// diagonalLoad adds a multiple of the average diagonal power to the diagonal
+// of R, regularizing it before inversion.
+func diagonalLoad(R [][]complex128, factor float64) [][]complex128 {
+	var trace float64
+	for i := range R {
+		trace += real(R[i][i])
+	}
+	load := complex(factor*trace/float64(len(R)), 0)
+	L := make([][]complex128, len(R))
+	for i := range R {
+		L[i] = make([]complex128, len(R[i]))
+		copy(L[i], R[i])
+		L[i][i] += load
+	}
+	return L
+}
+
+// eigHermitian returns the eigenvalues of a Hermitian matrix in descending
+// order along with the corresponding unit eigenvectors. The matrix is embedded
+// as a real symmetric matrix of twice the size and diagonalized with cyclic
+// Jacobi rotations.
+func eigHermitian(A [][]complex128) ([]float64, [][]complex128) {
+	n := len(A)
+	S := make([][]float64, 2*n)
+	for i := range S {
+		S[i] = make([]float64, 2*n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			re, im := real(A[i][j]), imag(A[i][j])
+			S[i][j] = re
+			S[i+n][j+n] = re
+			S[i][j+n] = -im
+			S[i+n][j] = im
+		}
+	}
+	vals, vecs := eigSymmetric(S)
+
+	// each complex eigenvector appears twice in the embedding; keep the first
+	// of each by orthogonalizing against those already chosen
+	var cvals []float64
+	var cvecs [][]complex128
+	for k := 0; k < 2*n && len(cvecs) < n; k++ {
+		v := make([]complex128, n)
+		for i := 0; i < n; i++ {
+			v[i] = complex(vecs[i][k], vecs[i+n][k])
+		}
+		for _, u := range cvecs {
+			p := cdot(u, v)
+			for i := range v {
+				v[i] -= p * u[i]
+			}
+		}
+		norm := math.Sqrt(real(cdot(v, v)))
+		if norm < 0.5 {
+			continue
+		}
+		for i := range v {
+			v[i] /= complex(norm, 0)
+		}
+		cvals = append(cvals, vals[k])
+		cvecs = append(cvecs, v)
+	}
+	return cvals, cvecs
+}
+
+// eigSymmetric diagonalizes a real symmetric matrix with cyclic Jacobi
+// rotations. It returns the eigenvalues in descending order and a matrix whose
+// columns are the corresponding eigenvectors.
+func eigSymmetric(S [][]float64) ([]float64, [][]float64) {
+	n := len(S)
+	A := make([][]float64, n)
+	V := make([][]float64, n)
+	for i := range S {
+		A[i] = make([]float64, n)
+		copy(A[i], S[i])
+		V[i] = make([]float64, n)
+		V[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		var off float64
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += A[i][j] * A[i][j]
+			}
+		}
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if A[p][q] == 0 {
+					continue
+				}
+				theta := (A[q][q] - A[p][p]) / (2 * A[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := A[k][p], A[k][q]
+					A[k][p] = c*akp - s*akq
+					A[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := A[p][k], A[q][k]
+					A[p][k] = c*apk - s*aqk
+					A[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := V[k][p], V[k][q]
+					V[k][p] = c*vkp - s*vkq
+					V[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return A[order[i]][order[i]] > A[order[j]][order[j]] })
+	vals := make([]float64, n)
+	vecs := make([][]float64, n)
+	for i := range vecs {
+		vecs[i] = make([]float64, n)
+	}
+	for k, idx := range order {
+		vals[k] = A[idx][idx]
+		for i := 0; i < n; i++ {
+			vecs[i][k] = V[i][idx]
+		}
+	}
+	return vals, vecs
+}
+
+// solveLinear solves the square system A x = b with Gaussian elimination and
+// partial pivoting. It returns nil if the matrix is singular.
+func solveLinear(A [][]float64, b []float64) []float64 {
+	n := len(A)
+	M := make([][]float64, n)
+	for i := range A {
+		M[i] = make([]float64, n+1)
+		copy(M[i], A[i])
+		M[i][n] = b[i]
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(M[r][col]) > math.Abs(M[pivot][col]) {
+				pivot = r
+			}
+		}
+		if M[pivot][col] == 0 {
+			return nil
+		}
+		M[col], M[pivot] = M[pivot], M[col]
+		for r := col + 1; r < n; r++ {
+			f := M[r][col] / M[col][col]
+			for j := col; j <= n; j++ {
+				M[r][j] -= f * M[col][j]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := M[i][n]
+		for j := i + 1; j < n; j++ {
+			sum -= M[i][j] * x[j]
+		}
+		x[i] = sum / M[i][i]
+	}
+	return x
+}
+
+// leastSquares returns the coefficients minimizing |X c - y|^2, where each row
+// of X is one observation, by solving the normal equations. A small ridge
+// term proportional to the average diagonal keeps them well conditioned.
+func leastSquares(X [][]float64, y []float64, ridge float64) []float64 {
+	if len(X) == 0 {
+		return nil
+	}
+	p := len(X[0])
+	A := make([][]float64, p)
+	for i := range A {
+		A[i] = make([]float64, p)
+	}
+	b := make([]float64, p)
+	for r, row := range X {
+		for i := 0; i < p; i++ {
+			b[i] += row[i] * y[r]
+			for j := 0; j < p; j++ {
+				A[i][j] += row[i] * row[j]
+			}
+		}
+	}
+	var trace float64
+	for i := 0; i < p; i++ {
+		trace += A[i][i]
+	}
+	for i := 0; i < p; i++ {
+		A[i][i] += ridge * trace / float64(p)
+	}
+	return solveLinear(A, b)
+}
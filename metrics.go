@@ -0,0 +1,85 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/rand"
+)
+
+// SNR returns the signal to noise ratio in dB of actual relative to expected,
+// treating their difference as noise. Identical signals return +Inf.
+func SNR(expected, actual DataSet) float64 {
+	checkLengths("SNR", expected, actual)
+	var signal, noise float64
+	for i, v := range expected {
+		e := actual[i] - v
+		signal += v * v
+		noise += e * e
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+// MaxAbsError returns the largest absolute difference between the samples of
+// expected and actual.
+func MaxAbsError(expected, actual DataSet) float64 {
+	checkLengths("MaxAbsError", expected, actual)
+	var max float64
+	for i, v := range expected {
+		max = math.Max(max, math.Abs(actual[i]-v))
+	}
+	return max
+}
+
+// RMSError returns the root mean square difference between expected and
+// actual.
+func RMSError(expected, actual DataSet) float64 {
+	checkLengths("RMSError", expected, actual)
+	if len(expected) == 0 {
+		return 0
+	}
+	var sum float64
+	for i, v := range expected {
+		e := actual[i] - v
+		sum += e * e
+	}
+	return math.Sqrt(sum / float64(len(expected)))
+}
+
+// SpectralError returns the difference between the magnitude spectra of
+// expected and actual, relative to the energy of the expected spectrum. Unlike
+// the time domain metrics it ignores phase, so a delayed but otherwise exact
+// signal scores close to zero.
+func SpectralError(expected, actual DataSet) float64 {
+	checkLengths("SpectralError", expected, actual)
+	n := NextPow2(len(expected))
+	E := Magnitude(FFT(realToComplex(expected, n))[:n/2+1])
+	A := Magnitude(FFT(realToComplex(actual, n))[:n/2+1])
+	var diff, ref float64
+	for k := range E {
+		d := A[k] - E[k]
+		diff += d * d
+		ref += E[k] * E[k]
+	}
+	if ref == 0 {
+		return math.Sqrt(diff)
+	}
+	return math.Sqrt(diff / ref)
+}
+
+// RandomSignal returns n samples of unit variance Gaussian noise drawn from a
+// source with the given seed, so tests built on it are repeatable bit for bit.
+func RandomSignal(n int, seed int64) DataSet {
+	rng := rand.New(rand.NewSource(seed))
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = rng.NormFloat64()
+	}
+	return values
+}
+
+// checkLengths stops the program if a and b differ in length.
+func checkLengths(name string, a, b DataSet) {
+	if len(a) != len(b) {
+		log.Fatalf("%s requires data sets of equal length, got %d and %d", name, len(a), len(b))
+	}
+}
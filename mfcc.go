@@ -0,0 +1,103 @@
+package dsp
+
+import "math"
+
+// MelFilters is the number of mel filters used by MFCC.
+var MelFilters = 26
+
+// melFloor keeps the log filter bank energies finite for silent frames.
+const melFloor = 1e-10
+
+// HzToMel converts a frequency in Hz to the mel scale.
+func HzToMel(f float64) float64 {
+	return 2595 * math.Log10(1+f/700)
+}
+
+// MelToHz converts a mel scale value to a frequency in Hz.
+func MelToHz(m float64) float64 {
+	return 700 * (math.Pow(10, m/2595) - 1)
+}
+
+// MelFilterBank returns n triangular filters spaced evenly on the mel scale
+// between fMin and fMax, each with a peak of 1, as weights over the nfft/2+1
+// one-sided bins of an nfft point FFT.
+func MelFilterBank(n, nfft int, fS, fMin, fMax float64) [][]float64 {
+	lo, hi := HzToMel(fMin), HzToMel(fMax)
+	edges := make([]float64, n+2)
+	for i := range edges {
+		edges[i] = MelToHz(lo + (hi-lo)*float64(i)/float64(n+1))
+	}
+
+	freqs := rfftFreqs(nfft, fS)
+	bank := make([][]float64, n)
+	for m := range bank {
+		left, center, right := edges[m], edges[m+1], edges[m+2]
+		bank[m] = make([]float64, len(freqs))
+		for k, f := range freqs {
+			switch {
+			case f > left && f <= center:
+				bank[m][k] = (f - left) / (center - left)
+			case f > center && f < right:
+				bank[m][k] = (right - f) / (right - center)
+			}
+		}
+	}
+	return bank
+}
+
+// MFCC returns the first nCoeffs mel frequency cepstral coefficients of a
+// frame: the orthonormal DCT of the log energies of MelFilters mel filters
+// applied to the power spectrum of the Hamming windowed frame.
+func MFCC(frame DataSet, fS float64, nCoeffs int) DataSet {
+	nfft := NextPow2(len(frame))
+	X := realToComplex(frame.ApplyWindow(Symmetric(Hamming, len(frame))), nfft)
+	fft(X, false)
+	power := make([]float64, nfft/2+1)
+	for k := range power {
+		power[k] = real(X[k])*real(X[k]) + imag(X[k])*imag(X[k])
+	}
+	bank := MelFilterBank(MelFilters, nfft, fS, 0, fS/2)
+	return melCepstrum(power, bank, nCoeffs)
+}
+
+// MFCC returns the first nCoeffs mel frequency cepstral coefficients of every
+// frame of the spectrogram.
+func (s *Spectrogram) MFCC(fS float64, nCoeffs int) [][]float64 {
+	bank := MelFilterBank(MelFilters, NextPow2(s.WindowSize), fS, 0, fS/2)
+	coeffs := make([][]float64, len(s.Data))
+	for t, frame := range s.Data {
+		power := make([]float64, len(frame))
+		for k, v := range frame {
+			power[k] = real(v)*real(v) + imag(v)*imag(v)
+		}
+		coeffs[t] = melCepstrum(power, bank, nCoeffs)
+	}
+	return coeffs
+}
+
+// melCepstrum applies the filter bank to a power spectrum and returns the
+// first nCoeffs coefficients of the orthonormal DCT of the log energies.
+func melCepstrum(power []float64, bank [][]float64, nCoeffs int) DataSet {
+	energies := make(DataSet, len(bank))
+	for m, weights := range bank {
+		var e float64
+		for k, w := range weights {
+			e += w * power[k]
+		}
+		energies[m] = math.Log(math.Max(e, melFloor))
+	}
+
+	c := energies.DCT()
+	n := float64(len(c))
+	for k := range c {
+		if k == 0 {
+			c[k] *= math.Sqrt(1 / (4 * n))
+		} else {
+			c[k] *= math.Sqrt(1 / (2 * n))
+		}
+	}
+	if nCoeffs < len(c) {
+		c = c[:nCoeffs]
+	}
+	return c
+}
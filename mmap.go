@@ -0,0 +1,178 @@
+package dsp
+
+import (
+	"encoding/binary"
+	"log"
+	"math"
+	"os"
+)
+
+// SampleFormat is the binary encoding of the samples in a file. All formats
+// are little-endian.
+type SampleFormat int
+
+const (
+	// Float64Format stores each sample as an IEEE 754 double.
+	Float64Format SampleFormat = iota
+
+	// Float32Format stores each sample as an IEEE 754 single.
+	Float32Format
+
+	// Int16Format stores each sample as a signed 16 bit integer, scaled to
+	// the range -1 to 1.
+	Int16Format
+
+	// Int32Format stores each sample as a signed 32 bit integer, scaled to
+	// the range -1 to 1.
+	Int32Format
+)
+
+// Size returns the number of bytes per sample.
+func (f SampleFormat) Size() int {
+	switch f {
+	case Float32Format, Int32Format:
+		return 4
+	case Int16Format:
+		return 2
+	}
+	return 8
+}
+
+// decode returns the sample encoded at the start of b.
+func (f SampleFormat) decode(b []byte) float64 {
+	switch f {
+	case Float32Format:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case Int16Format:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case Int32Format:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+// encode writes the sample v at the start of b, clipping integer formats to
+// their range.
+func (f SampleFormat) encode(b []byte, v float64) {
+	switch f {
+	case Float32Format:
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+	case Int16Format:
+		v = math.Round(math.Max(-1, math.Min(1, v)) * (1 << 15))
+		binary.LittleEndian.PutUint16(b, uint16(int16(math.Min(v, math.MaxInt16))))
+	case Int32Format:
+		v = math.Round(math.Max(-1, math.Min(1, v)) * (1 << 31))
+		binary.LittleEndian.PutUint32(b, uint32(int32(math.Min(v, math.MaxInt32))))
+	default:
+		binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+	}
+}
+
+// MappedData is a read-only view of the samples in a binary file, memory
+// mapped where the platform supports it, so captures far larger than memory
+// can be processed a chunk at a time. Samples are decoded on access.
+type MappedData struct {
+	format SampleFormat
+	file   *os.File
+	data   []byte
+	n      int
+}
+
+// OpenMapped opens a file of raw samples in the given format. Trailing bytes
+// short of a whole sample are ignored. The view must be closed when done.
+func OpenMapped(path string, format SampleFormat) (*MappedData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m := &MappedData{format: format, file: f, n: int(info.Size()) / format.Size()}
+	if m.n > 0 {
+		if m.data, err = mmapFile(f, int(info.Size())); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Len returns the number of samples.
+func (m *MappedData) Len() int {
+	return m.n
+}
+
+// At returns sample i.
+func (m *MappedData) At(i int) (float64, error) {
+	if i < 0 || i >= m.n {
+		log.Fatalf("MappedData index %d out of range [0:%d]", i, m.n)
+	}
+	var buf [8]byte
+	size := m.format.Size()
+	if err := m.read(buf[:size], i*size); err != nil {
+		return 0, err
+	}
+	return m.format.decode(buf[:size]), nil
+}
+
+// Slice decodes the samples from start up to end into a new data set.
+func (m *MappedData) Slice(start, end int) (DataSet, error) {
+	if start < 0 || end > m.n || start > end {
+		log.Fatalf("MappedData slice [%d:%d] out of range [0:%d]", start, end, m.n)
+	}
+	d := make(DataSet, end-start)
+	if err := m.decodeInto(d, start); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Chunks calls fn with successive chunks of up to size samples and the index
+// of their first sample, stopping at the first error from reading the file or
+// from fn. The chunk is reused between calls, so copy any samples that must
+// outlive the call.
+func (m *MappedData) Chunks(size int, fn func(start int, chunk DataSet) error) error {
+	if size <= 0 {
+		log.Fatalf("MappedData.Chunks requires a positive size, got %d", size)
+	}
+	buf := make(DataSet, size)
+	for start := 0; start < m.n; start += size {
+		chunk := buf[:minInt(size, m.n-start)]
+		if err := m.decodeInto(chunk, start); err != nil {
+			return err
+		}
+		if err := fn(start, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close unmaps and closes the file.
+func (m *MappedData) Close() error {
+	var err error
+	if m.data != nil {
+		err = munmap(m.data)
+		m.data = nil
+	}
+	if cerr := m.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// decodeInto decodes len(d) samples starting at sample start.
+func (m *MappedData) decodeInto(d DataSet, start int) error {
+	size := m.format.Size()
+	raw := make([]byte, len(d)*size)
+	if err := m.read(raw, start*size); err != nil {
+		return err
+	}
+	for i := range d {
+		d[i] = m.format.decode(raw[i*size:])
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package dsp
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// mmapFile does not map the file on this platform; samples are read from the
+// file as they are accessed instead.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, nil
+}
+
+// munmap does nothing on this platform.
+func munmap(b []byte) error {
+	return nil
+}
+
+// read reads the bytes at offset off into p.
+func (m *MappedData) read(p []byte, off int) error {
+	n, err := m.file.ReadAt(p, int64(off))
+	if n == len(p) {
+		return nil
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("dsp: MappedData read failed: %v", err)
+}
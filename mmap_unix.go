@@ -0,0 +1,25 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package dsp
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps size bytes of the file into memory read-only.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmap releases a mapping made by mmapFile.
+func munmap(b []byte) error {
+	return syscall.Munmap(b)
+}
+
+// read copies the bytes at offset off into p.
+func (m *MappedData) read(p []byte, off int) error {
+	copy(p, m.data[off:])
+	return nil
+}
@@ -0,0 +1,106 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+)
+
+// Resonance describes a resonant peak estimated with the half-power method.
+type Resonance struct {
+	// Frequency is the resonant frequency, refined by parabolic interpolation.
+	Frequency float64
+
+	// Peak is the spectrum value at the peak bin.
+	Peak float64
+
+	// Lower and Upper are the half-power (-3 dB) frequencies.
+	Lower, Upper float64
+
+	// Bandwidth is the -3 dB bandwidth, Upper - Lower.
+	Bandwidth float64
+
+	// Q is the quality factor, Frequency / Bandwidth.
+	Q float64
+
+	// Damping is the damping ratio, 1 / (2 Q).
+	Damping float64
+}
+
+// EstimateResonance estimates the parameters of the largest peak in a
+// spectrum. If power is true the spectrum holds power (such as a PSD) and the
+// half-power level is half the peak; otherwise it holds magnitudes (such as an
+// FRF) and the level is the peak divided by the square root of two.
+func EstimateResonance(freqs, spectrum DataSet, power bool) Resonance {
+	best := 0
+	for i := range spectrum {
+		if spectrum[i] > spectrum[best] {
+			best = i
+		}
+	}
+	return resonanceAt(freqs, spectrum, power, best)
+}
+
+// EstimateResonances estimates the parameters of the n largest local peaks in
+// a spectrum, in order of decreasing peak value.
+func EstimateResonances(freqs, spectrum DataSet, power bool, n int) []Resonance {
+	peaks := localMaxima(spectrum)
+	sort.Slice(peaks, func(i, j int) bool { return spectrum[peaks[i]] > spectrum[peaks[j]] })
+	if len(peaks) > n {
+		peaks = peaks[:n]
+	}
+	resonances := make([]Resonance, len(peaks))
+	for i, p := range peaks {
+		resonances[i] = resonanceAt(freqs, spectrum, power, p)
+	}
+	return resonances
+}
+
+// resonanceAt estimates the resonance parameters for the peak at bin p.
+func resonanceAt(freqs, spectrum DataSet, power bool, p int) Resonance {
+	peak := spectrum[p]
+	level := peak / math.Sqrt2
+	if power {
+		level = peak / 2
+	}
+
+	r := Resonance{Frequency: freqs[p], Peak: peak}
+	if p > 0 && p < len(spectrum)-1 {
+		a, b, c := spectrum[p-1], spectrum[p], spectrum[p+1]
+		if den := a - 2*b + c; den != 0 {
+			offset := 0.5 * (a - c) / den
+			r.Frequency = freqs[p] + offset*(freqs[p+1]-freqs[p])
+		}
+	}
+
+	// walk outwards to the half-power crossings
+	r.Lower = freqs[0]
+	for i := p; i > 0; i-- {
+		if spectrum[i-1] <= level {
+			r.Lower = crossing(freqs[i-1], freqs[i], spectrum[i-1], spectrum[i], level)
+			break
+		}
+	}
+	r.Upper = freqs[len(freqs)-1]
+	for i := p; i < len(spectrum)-1; i++ {
+		if spectrum[i+1] <= level {
+			r.Upper = crossing(freqs[i], freqs[i+1], spectrum[i], spectrum[i+1], level)
+			break
+		}
+	}
+
+	r.Bandwidth = r.Upper - r.Lower
+	if r.Bandwidth > 0 {
+		r.Q = r.Frequency / r.Bandwidth
+		r.Damping = 1 / (2 * r.Q)
+	}
+	return r
+}
+
+// crossing returns the frequency where the line between (f0, v0) and (f1, v1)
+// reaches the level.
+func crossing(f0, f1, v0, v1, level float64) float64 {
+	if v1 == v0 {
+		return f0
+	}
+	return f0 + (level-v0)/(v1-v0)*(f1-f0)
+}
@@ -0,0 +1,125 @@
+package dsp
+
+import "math"
+
+// NoiseFloor estimates the noise floor of a magnitude or power spectrum by
+// median filtering it over kernel bins. Narrow peaks occupy few bins and so
+// do not lift the estimate.
+func (d DataSet) NoiseFloor(kernel int) DataSet {
+	return d.MedianFilter(kernel)
+}
+
+// MinimumStatistics tracks the noise floor of successive power spectra by
+// recursively smoothing each bin and taking its minimum over a window of
+// recent frames (Martin's minimum statistics). The minimum follows the noise
+// during speech or other bursty signals, which rarely occupy a bin for the
+// whole window.
+type MinimumStatistics struct {
+	// Alpha is the smoothing factor applied to successive spectra.
+	Alpha float64
+
+	// Bias scales the tracked minimum to compensate for it underestimating
+	// the mean noise power.
+	Bias float64
+
+	window   int
+	smoothed []float64
+	history  [][]float64
+	pos      int
+}
+
+// NewMinimumStatistics creates a noise tracker taking the minimum over the
+// given number of frames.
+func NewMinimumStatistics(window int, alpha float64) *MinimumStatistics {
+	return &MinimumStatistics{Alpha: alpha, Bias: 1.5, window: window}
+}
+
+// Update adds a power spectrum and returns the current noise floor estimate.
+func (m *MinimumStatistics) Update(spectrum DataSet) DataSet {
+	if len(m.smoothed) != len(spectrum) {
+		m.smoothed = make([]float64, len(spectrum))
+		copy(m.smoothed, spectrum)
+		m.history = nil
+		m.pos = 0
+	}
+	for k, v := range spectrum {
+		m.smoothed[k] = m.Alpha*m.smoothed[k] + (1-m.Alpha)*v
+	}
+
+	frame := make([]float64, len(spectrum))
+	copy(frame, m.smoothed)
+	if len(m.history) < m.window {
+		m.history = append(m.history, frame)
+	} else {
+		m.history[m.pos] = frame
+		m.pos = (m.pos + 1) % m.window
+	}
+
+	floor := make([]float64, len(spectrum))
+	for k := range floor {
+		min := math.Inf(1)
+		for _, h := range m.history {
+			if h[k] < min {
+				min = h[k]
+			}
+		}
+		floor[k] = m.Bias * min
+	}
+	return floor
+}
+
+// Reset clears the tracked spectra.
+func (m *MinimumStatistics) Reset() {
+	m.smoothed = nil
+	m.history = nil
+	m.pos = 0
+}
+
+// QuietSegments splits the data set into frames of frameLen samples and
+// returns those whose RMS is at or below the given percentile (0-100) of all
+// frame RMS values, merging adjacent quiet frames.
+func (d DataSet) QuietSegments(frameLen int, percentile float64) []Interval {
+	rms := d.frameRMS(frameLen)
+	threshold := rms.Percentile(percentile)
+
+	var segments []Interval
+	for i, v := range rms {
+		if v > threshold {
+			continue
+		}
+		start := i * frameLen
+		stop := minInt(len(d), start+frameLen)
+		if n := len(segments); n > 0 && segments[n-1].End == start {
+			segments[n-1].End = stop
+		} else {
+			segments = append(segments, Interval{start, stop})
+		}
+	}
+	return segments
+}
+
+// NoiseFloorRMS estimates the RMS noise level of the data set from its quiet
+// segments.
+func (d DataSet) NoiseFloorRMS(frameLen int, percentile float64) float64 {
+	var ssq float64
+	var n int
+	for _, seg := range d.QuietSegments(frameLen, percentile) {
+		for i := seg.Start; i < seg.End; i++ {
+			ssq += d[i] * d[i]
+		}
+		n += seg.Len()
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Sqrt(ssq / float64(n))
+}
+
+// frameRMS returns the RMS of each consecutive frame of frameLen samples.
+func (d DataSet) frameRMS(frameLen int) DataSet {
+	var rms []float64
+	for start := 0; start < len(d); start += frameLen {
+		rms = append(rms, d[start:minInt(len(d), start+frameLen)].RMS())
+	}
+	return rms
+}
@@ -0,0 +1,67 @@
+package dsp
+
+import "math"
+
+// Normalizer is a streaming Processor that standardizes samples to zero mean
+// and unit variance using running statistics. Each sample updates the mean
+// and variance before it is scaled, with older samples weighted down by the
+// forgetting factor so the statistics follow slow drift in level and gain.
+type Normalizer struct {
+	// Lambda is the forgetting factor, between 0 and 1. The statistics
+	// cover roughly the last 1/(1-Lambda) samples; 1 weights all samples
+	// equally.
+	Lambda float64
+
+	// Frozen stops the statistics updating, so that samples are scaled with
+	// fixed values, such as those learned during a calibration period.
+	Frozen bool
+
+	weight, mean, ssq float64
+}
+
+// NewNormalizer creates a normalizer with the given forgetting factor.
+func NewNormalizer(lambda float64) *Normalizer {
+	return &Normalizer{Lambda: lambda}
+}
+
+// Mean returns the current running mean.
+func (n *Normalizer) Mean() float64 {
+	return n.mean
+}
+
+// Stdev returns the current running standard deviation.
+func (n *Normalizer) Stdev() float64 {
+	if n.weight == 0 {
+		return 0
+	}
+	return math.Sqrt(n.ssq / n.weight)
+}
+
+// Update adds a sample to the statistics and returns it standardized.
+func (n *Normalizer) Update(x float64) float64 {
+	if !n.Frozen {
+		n.weight = n.Lambda*n.weight + 1
+		delta := x - n.mean
+		n.mean += delta / n.weight
+		n.ssq = n.Lambda*n.ssq + delta*(x-n.mean)
+	}
+	var variance float64
+	if n.weight > 0 {
+		variance = n.ssq / n.weight
+	}
+	return (x - n.mean) * cmvnScale(variance, true)
+}
+
+// Process standardizes a block of samples.
+func (n *Normalizer) Process(X []float64) []float64 {
+	Y := make([]float64, len(X))
+	for i, x := range X {
+		Y[i] = n.Update(x)
+	}
+	return Y
+}
+
+// Reset clears the statistics. The Frozen flag is left unchanged.
+func (n *Normalizer) Reset() {
+	n.weight, n.mean, n.ssq = 0, 0, 0
+}
@@ -0,0 +1,85 @@
+package dsp
+
+import "math/cmplx"
+
+// RisingEdges returns the fractional sample positions where the data set
+// crosses the level going upwards, linearly interpolated between samples.
+func (d DataSet) RisingEdges(level float64) DataSet {
+	var edges []float64
+	for i := 1; i < len(d); i++ {
+		if d[i-1] < level && d[i] >= level {
+			edges = append(edges, float64(i-1)+(level-d[i-1])/(d[i]-d[i-1]))
+		}
+	}
+	return edges
+}
+
+// RPM returns the shaft speed in revolutions per minute between successive
+// tachometer pulses, given as fractional sample positions.
+func RPM(pulses DataSet, pulsesPerRev int, fS float64) DataSet {
+	if len(pulses) < 2 {
+		return DataSet{}
+	}
+	rpm := make([]float64, len(pulses)-1)
+	for i := range rpm {
+		rev := (pulses[i+1] - pulses[i]) / fS * float64(pulsesPerRev)
+		rpm[i] = 60 / rev
+	}
+	return rpm
+}
+
+// AngularResample resamples the data set from uniform time steps to uniform
+// shaft angle steps of samplesPerRev samples per revolution, using the
+// tachometer pulse positions (fractional sample indices) to map angle to time.
+// The output spans the revolutions covered by the pulses. The data should be
+// low-pass filtered below the lowest shaft speed times samplesPerRev/2 first.
+func (d DataSet) AngularResample(pulses DataSet, pulsesPerRev, samplesPerRev int) DataSet {
+	if len(pulses) < 2 {
+		return DataSet{}
+	}
+	angles := make([]float64, len(pulses))
+	for i := range angles {
+		angles[i] = float64(i) / float64(pulsesPerRev)
+	}
+	timeAt := cubicSpline(angles, pulses)
+
+	index := make([]float64, len(d))
+	for i := range index {
+		index[i] = float64(i)
+	}
+	valueAt := cubicSpline(index, d)
+
+	n := int(angles[len(angles)-1] * float64(samplesPerRev))
+	out := make([]float64, n)
+	for i := range out {
+		t := timeAt(float64(i) / float64(samplesPerRev))
+		if t < 0 || t > float64(len(d)-1) {
+			continue
+		}
+		out[i] = valueAt(t)
+	}
+	return out
+}
+
+// OrderSpectrum returns the amplitude spectrum of angle-domain data against
+// shaft order (multiples of the rotation frequency), using a Hann window.
+func OrderSpectrum(angular DataSet, samplesPerRev int) (DataSet, DataSet) {
+	n := NextPow2(len(angular))
+	window := Hann(len(angular))
+	var wsum float64
+	X := make([]complex128, n)
+	for i, v := range angular {
+		X[i] = complex(v*window[i], 0)
+		wsum += window[i]
+	}
+	fft(X, false)
+
+	orders := make([]float64, n/2+1)
+	amps := make([]float64, n/2+1)
+	for k := range orders {
+		orders[k] = float64(k) * float64(samplesPerRev) / float64(n)
+		amps[k] = 2 * cmplx.Abs(X[k]) / wsum
+	}
+	amps[0] /= 2
+	return orders, amps
+}
@@ -0,0 +1,113 @@
+package dsp
+
+import "math"
+
+// PhaseNoise is a single-sideband phase noise measurement.
+type PhaseNoise struct {
+	// Carrier is the measured frequency of the tone.
+	Carrier float64
+
+	// Offsets holds the offset frequencies from the carrier.
+	Offsets DataSet
+
+	// Level holds the phase noise at each offset in dBc/Hz.
+	Level DataSet
+}
+
+// PhaseNoise estimates the single-sideband phase noise of a captured tone.
+// The phase of the analytic signal is unwrapped and the carrier removed by
+// subtracting the best fit line, leaving the phase deviation, whose Welch
+// PSD over segments of segmentLen samples gives L(f) = S(f)/2. Only phase
+// fluctuations are measured, so amplitude noise does not contribute. If
+// pointsPerDecade is positive the result is averaged into that many log
+// spaced bands per decade, which smooths the noisy high offsets the way
+// phase noise plots are usually read.
+func (d DataSet) PhaseNoise(fS float64, segmentLen, pointsPerDecade int) *PhaseNoise {
+	phase := Unwrap(d.AnalyticSignal().Phase())
+
+	// least squares line through the phase gives the carrier
+	n := float64(len(phase))
+	var st, sp, stt, stp float64
+	for i, p := range phase {
+		t := float64(i)
+		st += t
+		sp += p
+		stt += t * t
+		stp += t * p
+	}
+	slope := (n*stp - st*sp) / (n*stt - st*st)
+	offset := (sp - slope*st) / n
+	deviation := make(DataSet, len(phase))
+	for i, p := range phase {
+		deviation[i] = p - offset - slope*float64(i)
+	}
+
+	freqs, psd := deviation.WelchPSD(segmentLen, segmentLen/2, nil, fS)
+	freqs, psd = freqs[1:], psd[1:]
+	if pointsPerDecade > 0 {
+		freqs, psd = logSmooth(freqs, psd, pointsPerDecade)
+	}
+
+	pn := &PhaseNoise{
+		Carrier: slope * fS / (2 * math.Pi),
+		Offsets: freqs,
+		Level:   make(DataSet, len(psd)),
+	}
+	for i, v := range psd {
+		pn.Level[i] = 10 * math.Log10(v/2)
+	}
+	return pn
+}
+
+// RMSPhase integrates the phase noise between offsets f1 and f2 and returns
+// the RMS phase deviation in radians, counting both sidebands.
+func (p *PhaseNoise) RMSPhase(f1, f2 float64) float64 {
+	var sum float64
+	for i := 1; i < len(p.Offsets); i++ {
+		lo, hi := math.Max(p.Offsets[i-1], f1), math.Min(p.Offsets[i], f2)
+		if hi <= lo {
+			continue
+		}
+		a := math.Pow(10, p.Level[i-1]/10)
+		b := math.Pow(10, p.Level[i]/10)
+		sum += (hi - lo) * (a + b) / 2
+	}
+	return math.Sqrt(2 * sum)
+}
+
+// RMSJitter returns the RMS timing jitter in seconds corresponding to the
+// phase noise between offsets f1 and f2.
+func (p *PhaseNoise) RMSJitter(f1, f2 float64) float64 {
+	return p.RMSPhase(f1, f2) / (2 * math.Pi * p.Carrier)
+}
+
+// logSmooth averages a spectrum into bands spaced evenly in log frequency,
+// returning the mean frequency and mean value of each non-empty band.
+func logSmooth(freqs, values DataSet, perDecade int) (DataSet, DataSet) {
+	var fs, vs []float64
+	if len(freqs) == 0 || freqs[0] <= 0 {
+		return fs, vs
+	}
+	step := math.Pow(10, 1/float64(perDecade))
+	edge := freqs[0] * step
+	var fSum, vSum float64
+	count := 0
+	for i, f := range freqs {
+		for f >= edge {
+			if count > 0 {
+				fs = append(fs, fSum/float64(count))
+				vs = append(vs, vSum/float64(count))
+				fSum, vSum, count = 0, 0, 0
+			}
+			edge *= step
+		}
+		fSum += f
+		vSum += values[i]
+		count++
+	}
+	if count > 0 {
+		fs = append(fs, fSum/float64(count))
+		vs = append(vs, vSum/float64(count))
+	}
+	return fs, vs
+}
@@ -0,0 +1,124 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// polyMul returns the product of two polynomials given by their coefficients.
+func polyMul(a, b []float64) []float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return []float64{}
+	}
+	out := make([]float64, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			out[i+j] += x * y
+		}
+	}
+	return out
+}
+
+// polyRoots returns the roots of the polynomial p[0] x^n + p[1] x^(n-1) + ...
+// + p[n] using the Aberth-Ehrlich iteration. The roots of a real polynomial
+// are returned as exact conjugate pairs.
+func polyRoots(p []float64) []complex128 {
+	for len(p) > 0 && p[0] == 0 {
+		p = p[1:]
+	}
+	var roots []complex128
+	for len(p) > 1 && p[len(p)-1] == 0 {
+		roots = append(roots, 0)
+		p = p[:len(p)-1]
+	}
+	n := len(p) - 1
+	if n < 1 {
+		return roots
+	}
+
+	// start on a circle whose radius is the geometric mean of the roots
+	radius := math.Pow(math.Abs(p[n]/p[0]), 1/float64(n))
+	if radius == 0 || math.IsInf(radius, 0) || math.IsNaN(radius) {
+		radius = 1
+	}
+	z := make([]complex128, n)
+	for k := range z {
+		z[k] = cmplx.Rect(radius, 2*math.Pi*(float64(k)+0.25)/float64(n))
+	}
+
+	for iter := 0; iter < 500; iter++ {
+		converged := true
+		for k := range z {
+			v, dv := polyEval(p, z[k])
+			if v == 0 {
+				continue
+			}
+			w := v / dv
+			var sum complex128
+			for j := range z {
+				if j != k {
+					sum += 1 / (z[k] - z[j])
+				}
+			}
+			step := w / (1 - w*sum)
+			z[k] -= step
+			if cmplx.Abs(step) > 1e-14*math.Max(1, cmplx.Abs(z[k])) {
+				converged = false
+			}
+		}
+		if converged {
+			break
+		}
+	}
+	return append(roots, conjugatePairs(z)...)
+}
+
+// polyEval returns the value and derivative of p at z by Horner's rule.
+func polyEval(p []float64, z complex128) (complex128, complex128) {
+	var v, dv complex128
+	for _, c := range p {
+		dv = dv*z + v
+		v = v*z + complex(c, 0)
+	}
+	return v, dv
+}
+
+// conjugatePairs snaps numerically computed roots of a real polynomial to
+// exact conjugate pairs and real values.
+func conjugatePairs(roots []complex128) []complex128 {
+	sorted := make([]complex128, len(roots))
+	copy(sorted, roots)
+	sort.Slice(sorted, func(i, j int) bool {
+		return imag(sorted[i]) > imag(sorted[j])
+	})
+
+	used := make([]bool, len(sorted))
+	var out []complex128
+	for i, r := range sorted {
+		if used[i] || imag(r) <= 0 {
+			continue
+		}
+		match := -1
+		for j := len(sorted) - 1; j > i; j-- {
+			if used[j] || imag(sorted[j]) >= 0 {
+				continue
+			}
+			if match < 0 || cmplx.Abs(sorted[j]-cmplx.Conj(r)) < cmplx.Abs(sorted[match]-cmplx.Conj(r)) {
+				match = j
+			}
+		}
+		if match < 0 {
+			continue
+		}
+		used[i], used[match] = true, true
+		m := (r + cmplx.Conj(sorted[match])) / 2
+		out = append(out, m, cmplx.Conj(m))
+	}
+	for i, r := range sorted {
+		if !used[i] {
+			out = append(out, complex(real(r), 0))
+		}
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package dsp
+
+// Processor is a stateful stream processor which operates on successive
+// blocks of samples, carrying its internal state between calls.
+type Processor interface {
+	// Process consumes a block of samples and returns the output block.
+	Process(X []float64) []float64
+
+	// Reset clears the internal state.
+	Reset()
+}
+
+// RateChanger is implemented by processors whose output sample rate differs
+// from their input sample rate, such as decimators and interpolators.
+type RateChanger interface {
+	// RateRatio returns the output rate divided by the input rate as the
+	// fraction up/down.
+	RateRatio() (up, down int)
+}
+
+// Skipper is implemented by rate changers that can account for samples
+// dropped from their input, so that their output phase stays locked to the
+// sample index of the stream.
+type Skipper interface {
+	// Skip advances the stream position by n input samples that were lost
+	// and returns the number of output samples they would have produced.
+	Skip(n int) int
+}
+
+// Pipeline is a chain of processors run in order, each consuming the output
+// of the one before.
+type Pipeline []Processor
+
+// Process runs a block of samples through every stage.
+func (p Pipeline) Process(X []float64) []float64 {
+	for _, stage := range p {
+		X = stage.Process(X)
+	}
+	return X
+}
+
+// RateRatio returns the combined rate change of the stages that implement
+// RateChanger.
+func (p Pipeline) RateRatio() (up, down int) {
+	up, down = 1, 1
+	for _, stage := range p {
+		if r, ok := stage.(RateChanger); ok {
+			u, d := r.RateRatio()
+			up, down = reduceRatio(up*u, down*d)
+		}
+	}
+	return up, down
+}
+
+// Skip passes the dropped samples through every stage, converting the count
+// at each rate changer.
+func (p Pipeline) Skip(n int) int {
+	return skipStages(p, n)
+}
+
+// Reset clears the state of every stage.
+func (p Pipeline) Reset() {
+	for _, stage := range p {
+		stage.Reset()
+	}
+}
+
+// StreamFilter executes a Filter over a stream of blocks, keeping the filter
+// delays between calls. Its coefficients can be ramped smoothly to a new
+// design to avoid zipper noise when parameters change at runtime.
+type StreamFilter struct {
+	f Filter
+	z []float64
+
+	// coefficient ramp
+	target    Filter
+	dA, dB    []float64
+	remaining int
+	perBlock  bool
+}
+
+// NewStreamFilter creates a streaming filter starting with the given design.
+func NewStreamFilter(f *Filter) *StreamFilter {
+	return &StreamFilter{f: padFilter(f, len(f.A)), z: make([]float64, len(f.A))}
+}
+
+// Filter returns a copy of the current coefficients.
+func (s *StreamFilter) Filter() *Filter {
+	f := padFilter(&s.f, len(s.f.A))
+	return &f
+}
+
+// Set replaces the coefficients immediately, cancelling any ramp in progress.
+func (s *StreamFilter) Set(f *Filter) {
+	s.resize(len(f.A))
+	s.f = padFilter(f, len(s.f.A))
+	s.remaining = 0
+}
+
+// Ramp linearly interpolates the coefficients towards the target design over
+// the next n samples. Linear interpolation between two stable second order
+// sections is itself stable, so sweeping a biquad is safe.
+func (s *StreamFilter) Ramp(to *Filter, n int) {
+	s.startRamp(to, n, false)
+}
+
+// RampBlocks interpolates the coefficients towards the target design over the
+// next n calls to Process, updating them once per block.
+func (s *StreamFilter) RampBlocks(to *Filter, n int) {
+	s.startRamp(to, n, true)
+}
+
+// Ramping returns true while a coefficient ramp is in progress.
+func (s *StreamFilter) Ramping() bool {
+	return s.remaining > 0
+}
+
+// Process filters a block of samples.
+func (s *StreamFilter) Process(X []float64) []float64 {
+	if s.perBlock && s.remaining > 0 {
+		s.step()
+	}
+
+	n := len(s.f.A)
+	Y := make([]float64, len(X))
+	for m := 0; m < len(X); m++ {
+		if !s.perBlock && s.remaining > 0 {
+			s.step()
+		}
+		Y[m] = s.f.A[0]*X[m] + s.z[0]
+		for i := 1; i < n; i++ {
+			s.z[i-1] = s.f.A[i]*X[m] + s.z[i] - s.f.B[i]*Y[m]
+		}
+	}
+	return Y
+}
+
+// Reset clears the filter delays. Any ramp in progress is completed.
+func (s *StreamFilter) Reset() {
+	for i := range s.z {
+		s.z[i] = 0
+	}
+	if s.remaining > 0 {
+		s.f = s.target
+		s.remaining = 0
+	}
+}
+
+// startRamp sets up the per-step coefficient increments.
+func (s *StreamFilter) startRamp(to *Filter, n int, perBlock bool) {
+	if n <= 0 {
+		s.Set(to)
+		return
+	}
+	s.resize(len(to.A))
+	size := len(s.f.A)
+	s.target = padFilter(to, size)
+	s.dA = make([]float64, size)
+	s.dB = make([]float64, size)
+	for i := 0; i < size; i++ {
+		s.dA[i] = (s.target.A[i] - s.f.A[i]) / float64(n)
+		s.dB[i] = (s.target.B[i] - s.f.B[i]) / float64(n)
+	}
+	s.remaining = n
+	s.perBlock = perBlock
+}
+
+// step advances the coefficient ramp by one increment.
+func (s *StreamFilter) step() {
+	s.remaining--
+	if s.remaining == 0 {
+		copy(s.f.A, s.target.A)
+		copy(s.f.B, s.target.B)
+		return
+	}
+	for i := range s.f.A {
+		s.f.A[i] += s.dA[i]
+		s.f.B[i] += s.dB[i]
+	}
+}
+
+// resize grows the coefficient and delay buffers to hold a filter of size n.
+func (s *StreamFilter) resize(n int) {
+	if n <= len(s.f.A) {
+		return
+	}
+	s.f = padFilter(&s.f, n)
+	z := make([]float64, n)
+	copy(z, s.z)
+	s.z = z
+}
+
+// padFilter copies the filter coefficients, zero-padding them to size n.
+func padFilter(f *Filter, n int) Filter {
+	p := Filter{B: make([]float64, n), A: make([]float64, n)}
+	copy(p.A, f.A)
+	copy(p.B, f.B)
+	return p
+}
+
+// skipStages skips n input samples in each stage in turn and returns the
+// number of samples skipped at the output of the last.
+func skipStages(stages []Processor, n int) int {
+	for _, stage := range stages {
+		switch s := stage.(type) {
+		case Skipper:
+			n = s.Skip(n)
+		case RateChanger:
+			up, down := s.RateRatio()
+			n = n * up / down
+		}
+	}
+	return n
+}
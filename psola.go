@@ -0,0 +1,127 @@
+package dsp
+
+import "math"
+
+// pitch analysis frame length and voicing threshold
+const (
+	pitchFrameSeconds = 0.04
+	pitchVoicing      = 0.3
+)
+
+// PitchMarks returns pitch-synchronous analysis marks for the data set. Each
+// mark sits on a waveform peak one local pitch period after the previous one,
+// with the period estimated by autocorrelation in the range [minF0, maxF0].
+// Unvoiced regions are marked at a constant rate of minF0.
+func (d DataSet) PitchMarks(fS, minF0, maxF0 float64) []int {
+	periods := d.pitchPeriods(fS, minF0, maxF0)
+	frame := int(pitchFrameSeconds * fS)
+	hop := maxInt(frame/4, 1)
+
+	var marks []int
+	pos := 0
+	for pos < len(d) {
+		period := periods[minInt(pos/hop, len(periods)-1)]
+
+		// snap to the largest peak within a quarter period
+		best := pos
+		for i := pos; i < minInt(len(d), pos+period/4+1); i++ {
+			if d[i] > d[best] {
+				best = i
+			}
+		}
+		if len(marks) == 0 || best > marks[len(marks)-1] {
+			marks = append(marks, best)
+		}
+		pos = best + period - period/8
+	}
+	return marks
+}
+
+// PSOLA modifies the duration and pitch of a monophonic signal with
+// time-domain pitch-synchronous overlap-add. A timeScale of 2 doubles the
+// duration and a pitchScale of 2 raises the pitch an octave. Two-period Hann
+// grains centered on the analysis pitch marks are re-spaced at the new pitch
+// period, which preserves formants far better than resampling.
+func (d DataSet) PSOLA(fS, minF0, maxF0, timeScale, pitchScale float64) DataSet {
+	marks := d.PitchMarks(fS, minF0, maxF0)
+	if len(marks) < 2 {
+		values := make([]float64, len(d))
+		copy(values, d)
+		return values
+	}
+
+	// local period at each mark
+	periods := make([]int, len(marks))
+	for i := range marks {
+		if i+1 < len(marks) {
+			periods[i] = marks[i+1] - marks[i]
+		} else {
+			periods[i] = periods[i-1]
+		}
+	}
+
+	length := int(float64(len(d)) * timeScale)
+	out := make([]float64, length)
+	norm := make([]float64, length)
+	ts := float64(marks[0])
+	for ts < float64(length) {
+		// nearest analysis mark to the corresponding input time
+		ta := ts / timeScale
+		i := 0
+		for i+1 < len(marks) && math.Abs(float64(marks[i+1])-ta) < math.Abs(float64(marks[i])-ta) {
+			i++
+		}
+
+		period := periods[i]
+		center := int(ts)
+		for k := -period; k < period; k++ {
+			src := marks[i] + k
+			dst := center + k
+			if src < 0 || src >= len(d) || dst < 0 || dst >= length {
+				continue
+			}
+			w := 0.5 + 0.5*math.Cos(math.Pi*float64(k)/float64(period))
+			out[dst] += w * d[src]
+			norm[dst] += w
+		}
+		ts += math.Max(1, float64(period)/pitchScale)
+	}
+
+	for i := range out {
+		if norm[i] > 1 {
+			out[i] /= norm[i]
+		}
+	}
+	return out
+}
+
+// pitchPeriods estimates the pitch period in samples for each analysis frame
+// from the peak of the normalized autocorrelation.
+func (d DataSet) pitchPeriods(fS, minF0, maxF0 float64) []int {
+	frame := int(pitchFrameSeconds * fS)
+	hop := maxInt(frame/4, 1)
+	minLag := maxInt(int(fS/maxF0), 1)
+	maxLag := maxInt(int(fS/minF0), minLag)
+	unvoiced := maxLag
+
+	var periods []int
+	for start := 0; start < len(d) || len(periods) == 0; start += hop {
+		seg := d[start:minInt(len(d), start+frame)]
+		period := unvoiced
+		if len(seg) > maxLag {
+			r := seg.Autocorrelation(maxLag)
+			best := 0.0
+			for lag := minLag; lag <= maxLag; lag++ {
+				if r[0] > 0 && r[lag]/r[0] > best {
+					best = r[lag] / r[0]
+					period = lag
+				}
+			}
+			if best < pitchVoicing {
+				period = unvoiced
+			}
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
@@ -0,0 +1,101 @@
+package dsp
+
+// FallingEdges returns the fractional sample positions where the data set
+// crosses the level going downwards, linearly interpolated between samples.
+func (d DataSet) FallingEdges(level float64) DataSet {
+	var edges []float64
+	for i := 1; i < len(d); i++ {
+		if d[i-1] >= level && d[i] < level {
+			edges = append(edges, float64(i-1)+(d[i-1]-level)/(d[i-1]-d[i]))
+		}
+	}
+	return edges
+}
+
+// PulseTrain describes the pulses of a thresholded signal, such as a PWM
+// output or a digital line captured by an analog input. All times are in
+// seconds.
+type PulseTrain struct {
+	// Rising and Falling are the edge times.
+	Rising  DataSet
+	Falling DataSet
+
+	// Widths is the high time of each complete pulse, from a rising edge to
+	// the next falling edge.
+	Widths DataSet
+
+	// Gaps is the low time after each complete pulse, up to the next rising
+	// edge.
+	Gaps DataSet
+
+	// Periods is the time between successive rising edges.
+	Periods DataSet
+
+	// Duty is the fraction of each period spent high.
+	Duty DataSet
+}
+
+// Pulses finds the pulses of the data set above the level and measures their
+// widths, gaps, periods and duty cycles. Edges are interpolated between
+// samples, so the timing resolution is finer than the sample period. Pulses
+// cut off by the start or end of the data are not measured.
+func (d DataSet) Pulses(level, fS float64) *PulseTrain {
+	rising := d.RisingEdges(level)
+	falling := d.FallingEdges(level)
+	p := &PulseTrain{
+		Rising:  make(DataSet, len(rising)),
+		Falling: make(DataSet, len(falling)),
+	}
+	for i, r := range rising {
+		p.Rising[i] = r / fS
+	}
+	for i, f := range falling {
+		p.Falling[i] = f / fS
+	}
+
+	j := 0
+	for i, r := range p.Rising {
+		for j < len(p.Falling) && p.Falling[j] <= r {
+			j++
+		}
+		if j == len(p.Falling) {
+			break
+		}
+		if i+1 == len(p.Rising) {
+			p.Widths = append(p.Widths, p.Falling[j]-r)
+			break
+		}
+		next := p.Rising[i+1]
+		period := next - r
+		width := p.Falling[j] - r
+		p.Widths = append(p.Widths, width)
+		p.Gaps = append(p.Gaps, next-p.Falling[j])
+		p.Periods = append(p.Periods, period)
+		p.Duty = append(p.Duty, width/period)
+	}
+	return p
+}
+
+// DutyCycle returns the mean duty cycle, between 0 and 1.
+func (p *PulseTrain) DutyCycle() float64 {
+	return p.Duty.Mean()
+}
+
+// Frequency returns the pulse repetition frequency from the mean period.
+func (p *PulseTrain) Frequency() float64 {
+	if len(p.Periods) == 0 {
+		return 0
+	}
+	return 1 / p.Periods.Mean()
+}
+
+// PeriodJitter returns the standard deviation of the periods, which measures
+// the timing jitter of the rising edges.
+func (p *PulseTrain) PeriodJitter() float64 {
+	return p.Periods.Stdev()
+}
+
+// WidthJitter returns the standard deviation of the pulse widths.
+func (p *PulseTrain) WidthJitter() float64 {
+	return p.Widths.Stdev()
+}
@@ -0,0 +1,91 @@
+package dsp
+
+import "math"
+
+// Cycle is a load cycle extracted by rainflow counting. Count is 1 for a full
+// cycle and 0.5 for a half cycle.
+type Cycle struct {
+	Range, Mean, Count float64
+}
+
+// Reversals returns the turning points of the data set: the first and last
+// samples along with every local peak and valley.
+func (d DataSet) Reversals() DataSet {
+	if len(d) < 3 {
+		values := make([]float64, len(d))
+		copy(values, d)
+		return values
+	}
+	reversals := []float64{d[0]}
+	for i := 1; i < len(d)-1; i++ {
+		prev := reversals[len(reversals)-1]
+		if (d[i]-prev)*(d[i+1]-d[i]) < 0 {
+			reversals = append(reversals, d[i])
+		}
+	}
+	if d[len(d)-1] != reversals[len(reversals)-1] {
+		reversals = append(reversals, d[len(d)-1])
+	}
+	return reversals
+}
+
+// Rainflow counts the load cycles in the data set with the ASTM E1049 rainflow
+// algorithm. Ranges that do not close a loop are counted as half cycles.
+func (d DataSet) Rainflow() []Cycle {
+	var cycles []Cycle
+	var stack []float64
+	for _, point := range d.Reversals() {
+		stack = append(stack, point)
+		for len(stack) >= 3 {
+			n := len(stack)
+			x := math.Abs(stack[n-1] - stack[n-2])
+			y := math.Abs(stack[n-2] - stack[n-3])
+			if x < y {
+				break
+			}
+			mean := (stack[n-2] + stack[n-3]) / 2
+			if n == 3 {
+				// the range contains the starting point
+				cycles = append(cycles, Cycle{Range: y, Mean: mean, Count: 0.5})
+				stack = stack[1:]
+			} else {
+				cycles = append(cycles, Cycle{Range: y, Mean: mean, Count: 1})
+				stack = append(stack[:n-3], stack[n-1])
+			}
+		}
+	}
+	for i := 1; i < len(stack); i++ {
+		cycles = append(cycles, Cycle{
+			Range: math.Abs(stack[i] - stack[i-1]),
+			Mean:  (stack[i] + stack[i-1]) / 2,
+			Count: 0.5,
+		})
+	}
+	return cycles
+}
+
+// RainflowHistogram bins the cycle counts by range into the given number of
+// equal-width bins from zero to the largest range. It returns the count in
+// each bin and the bin edges.
+func RainflowHistogram(cycles []Cycle, bins int) (DataSet, DataSet) {
+	var maxRange float64
+	for _, c := range cycles {
+		maxRange = math.Max(maxRange, c.Range)
+	}
+	counts := make([]float64, bins)
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = maxRange * float64(i) / float64(bins)
+	}
+	if maxRange == 0 {
+		return counts, edges
+	}
+	for _, c := range cycles {
+		b := int(c.Range / maxRange * float64(bins))
+		if b >= bins {
+			b = bins - 1
+		}
+		counts[b] += c.Count
+	}
+	return counts, edges
+}
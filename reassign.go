@@ -0,0 +1,166 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// reassignFloor is the magnitude, relative to the largest in the transform,
+// below which bins are too noisy to reassign and are dropped.
+const reassignFloor = 1e-6
+
+// TimeFrequency is a real valued time-frequency distribution. Data holds one
+// row of values per time, with one value per frequency.
+type TimeFrequency struct {
+	Data  [][]float64
+	Times DataSet
+	Freqs DataSet
+}
+
+// Reassigned computes the reassigned spectrogram of the data set. The energy
+// of each STFT bin is moved from the center of its cell to the local center
+// of gravity of the signal energy, estimated from STFTs with time weighted
+// and differentiated windows, which sharpens tones, chirps and impulses far
+// beyond the resolution of the window. If window is nil, a Hann window is
+// used. The result shares its grid with the plain spectrogram.
+func (d DataSet) Reassigned(windowSize, hop int, window DataSet, fS float64) *TimeFrequency {
+	s := d.STFT(windowSize, hop, window)
+	tf := &TimeFrequency{
+		Data:  make([][]float64, len(s.Data)),
+		Times: s.Times(fS),
+		Freqs: s.Frequencies(fS),
+	}
+	for t := range tf.Data {
+		tf.Data[t] = make([]float64, len(tf.Freqs))
+	}
+
+	d.reassign(s, func(t, k int, tHat, kHat float64, v complex128) {
+		ti, ki := int(math.Round(tHat)), int(math.Round(kHat))
+		if ti >= 0 && ti < len(tf.Data) && ki >= 0 && ki < len(tf.Freqs) {
+			tf.Data[ti][ki] += real(v)*real(v) + imag(v)*imag(v)
+		}
+	})
+	return tf
+}
+
+// Synchrosqueeze computes the synchrosqueezed STFT of the data set. Each
+// complex STFT value is moved along frequency, but not time, to the
+// instantaneous frequency estimated at that bin, concentrating each component
+// onto a sharp ridge. The values are phase referenced to the frame center, so
+// the transform stays invertible: summing a frame over frequency recovers the
+// sample at its center (see SynchrosqueezeInverse). If window is nil, a Hann
+// window is used.
+func (d DataSet) Synchrosqueeze(windowSize, hop int, window DataSet) *Spectrogram {
+	s := d.STFT(windowSize, hop, window)
+	nfft := NextPow2(windowSize)
+	center := windowSize / 2
+	out := &Spectrogram{
+		Data:       make([][]complex128, len(s.Data)),
+		WindowSize: s.WindowSize,
+		Hop:        s.Hop,
+		Window:     s.Window,
+	}
+	for t, frame := range s.Data {
+		out.Data[t] = make([]complex128, len(frame))
+	}
+
+	d.reassign(s, func(t, k int, tHat, kHat float64, v complex128) {
+		ki := int(math.Round(kHat))
+		if ki >= 0 && ki < len(out.Data[t]) {
+			shift := cmplx.Rect(1, 2*math.Pi*float64(k*center%nfft)/float64(nfft))
+			out.Data[t][ki] += v * shift
+		}
+	})
+	return out
+}
+
+// SynchrosqueezeInverse reconstructs the signal at the center of each frame of
+// a synchrosqueezed STFT, one sample per hop.
+func SynchrosqueezeInverse(s *Spectrogram) DataSet {
+	nfft := NextPow2(s.WindowSize)
+	gain := float64(nfft) * s.Window[s.WindowSize/2]
+	values := make([]float64, len(s.Data))
+	for t, frame := range s.Data {
+		var sum float64
+		for k, v := range frame {
+			if k == 0 || k == nfft/2 {
+				sum += real(v)
+			} else {
+				sum += 2 * real(v)
+			}
+		}
+		values[t] = sum / gain
+	}
+	return values
+}
+
+// reassign computes the reassigned frame and bin of every significant cell of
+// the spectrogram s of d and passes them to fn with the cell value.
+func (d DataSet) reassign(s *Spectrogram, fn func(t, k int, tHat, kHat float64, v complex128)) {
+	size := s.WindowSize
+	nfft := NextPow2(size)
+	center := float64(size / 2)
+
+	// time weighted and differentiated windows
+	tw := make([]float64, size)
+	dw := make([]float64, size)
+	for i := range tw {
+		tw[i] = (float64(i) - center) * s.Window[i]
+		var prev, next float64
+		if i > 0 {
+			prev = s.Window[i-1]
+		}
+		if i < size-1 {
+			next = s.Window[i+1]
+		}
+		dw[i] = (next - prev) / 2
+	}
+	Xt := stft(d, size, s.Hop, tw)
+	Xd := stft(d, size, s.Hop, dw)
+
+	var peak float64
+	for _, frame := range s.Data {
+		for _, v := range frame {
+			peak = math.Max(peak, cmplx.Abs(v))
+		}
+	}
+	floor := reassignFloor * peak
+
+	for t, frame := range s.Data {
+		for k, v := range frame {
+			if cmplx.Abs(v) <= floor {
+				continue
+			}
+			dt := real(Xt[t][k] / v)
+			dk := -imag(Xd[t][k]/v) * float64(nfft) / (2 * math.Pi)
+			fn(t, k, float64(t)+dt/float64(s.Hop), float64(k)+dk, v)
+		}
+	}
+}
+
+// TFPoint is a point of energy in the time-frequency plane.
+type TFPoint struct {
+	Time  float64
+	Freq  float64
+	Power float64
+}
+
+// ReassignedPoints returns the reassigned spectrogram of the data set as
+// scattered points at their exact reassigned times and frequencies, without
+// rounding them onto the STFT grid. This keeps the full precision of the
+// reassignment for short transients and fast chirps, whose points would
+// otherwise pile into a few cells. Times are measured like Spectrogram.Times,
+// from the start of the frame. If window is nil, a Hann window is used.
+func (d DataSet) ReassignedPoints(windowSize, hop int, window DataSet, fS float64) []TFPoint {
+	s := d.STFT(windowSize, hop, window)
+	nfft := float64(NextPow2(windowSize))
+	var points []TFPoint
+	d.reassign(s, func(t, k int, tHat, kHat float64, v complex128) {
+		points = append(points, TFPoint{
+			Time:  tHat * float64(hop) / fS,
+			Freq:  kHat * fS / nfft,
+			Power: real(v)*real(v) + imag(v)*imag(v),
+		})
+	})
+	return points
+}
@@ -0,0 +1,96 @@
+package dsp
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"math"
+)
+
+// Colormap maps a value between 0 and 1 to a color.
+type Colormap func(v float64) color.RGBA
+
+// Grayscale maps 0 to black and 1 to white.
+func Grayscale(v float64) color.RGBA {
+	g := uint8(math.Round(255 * clamp01(v)))
+	return color.RGBA{g, g, g, 255}
+}
+
+// Viridis approximates the perceptually uniform viridis colormap.
+var Viridis = gradient([][3]uint8{
+	{68, 1, 84}, {59, 82, 139}, {33, 145, 140}, {94, 201, 98}, {253, 231, 37},
+})
+
+// Inferno approximates the perceptually uniform inferno colormap.
+var Inferno = gradient([][3]uint8{
+	{0, 0, 4}, {87, 16, 110}, {188, 55, 84}, {249, 142, 9}, {252, 255, 164},
+})
+
+// Jet is the classic rainbow colormap from dark blue to dark red.
+var Jet = gradient([][3]uint8{
+	{0, 0, 128}, {0, 0, 255}, {0, 255, 255}, {255, 255, 0}, {255, 0, 0}, {128, 0, 0},
+})
+
+// RenderPNG writes the spectrogram as a PNG image with one column per frame,
+// time running left to right, and one row per bin, frequency increasing
+// upwards. Magnitudes are converted to dB relative to the largest and mapped
+// through the colormap from floorDB, a negative level such as -80, up to 0.
+// A nil colormap uses Viridis.
+func (s *Spectrogram) RenderPNG(w io.Writer, cmap Colormap, floorDB float64) error {
+	if floorDB >= 0 {
+		log.Fatalf("RenderPNG requires a negative floor, got %v dB", floorDB)
+	}
+	if cmap == nil {
+		cmap = Viridis
+	}
+	mag := s.Magnitude()
+	bins := 0
+	if len(mag) > 0 {
+		bins = len(mag[0])
+	}
+
+	var peak float64
+	for _, frame := range mag {
+		for _, v := range frame {
+			peak = math.Max(peak, v)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, len(mag), bins))
+	for t, frame := range mag {
+		for k, v := range frame {
+			db := floorDB
+			if v > 0 && peak > 0 {
+				db = math.Max(20*math.Log10(v/peak), floorDB)
+			}
+			img.SetRGBA(t, bins-1-k, cmap(1-db/floorDB))
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// gradient returns a colormap interpolating linearly between evenly spaced
+// colors.
+func gradient(stops [][3]uint8) Colormap {
+	return func(v float64) color.RGBA {
+		pos := clamp01(v) * float64(len(stops)-1)
+		i := int(pos)
+		if i >= len(stops)-1 {
+			i = len(stops) - 2
+		}
+		frac := pos - float64(i)
+		var c [3]uint8
+		for j := range c {
+			a, b := float64(stops[i][j]), float64(stops[i+1][j])
+			c[j] = uint8(math.Round(a + frac*(b-a)))
+		}
+		return color.RGBA{c[0], c[1], c[2], 255}
+	}
+}
+
+// clamp01 limits v to the range 0 to 1.
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
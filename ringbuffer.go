@@ -0,0 +1,74 @@
+package dsp
+
+// RingBuffer holds the most recent samples of a stream up to a fixed capacity,
+// overwriting the oldest samples when full.
+type RingBuffer struct {
+	data  []float64
+	start int
+	size  int
+}
+
+// NewRingBuffer creates a ring buffer holding up to capacity samples.
+func NewRingBuffer(capacity int) *RingBuffer {
+	return &RingBuffer{data: make([]float64, capacity)}
+}
+
+// Write appends samples to the buffer, discarding the oldest samples if the
+// capacity is exceeded.
+func (r *RingBuffer) Write(X []float64) {
+	c := len(r.data)
+	if len(X) >= c {
+		copy(r.data, X[len(X)-c:])
+		r.start = 0
+		r.size = c
+		return
+	}
+	for _, v := range X {
+		end := (r.start + r.size) % c
+		r.data[end] = v
+		if r.size < c {
+			r.size++
+		} else {
+			r.start = (r.start + 1) % c
+		}
+	}
+}
+
+// Len returns the number of samples in the buffer.
+func (r *RingBuffer) Len() int {
+	return r.size
+}
+
+// Cap returns the capacity of the buffer.
+func (r *RingBuffer) Cap() int {
+	return len(r.data)
+}
+
+// At returns the i-th oldest sample in the buffer.
+func (r *RingBuffer) At(i int) float64 {
+	return r.data[(r.start+i)%len(r.data)]
+}
+
+// Last returns a copy of the n most recent samples, oldest first. If fewer
+// than n samples are held, all of them are returned.
+func (r *RingBuffer) Last(n int) DataSet {
+	if n > r.size {
+		n = r.size
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = r.At(r.size - n + i)
+	}
+	return values
+}
+
+// Values returns a copy of every sample in the buffer, oldest first.
+func (r *RingBuffer) Values() DataSet {
+	return r.Last(r.size)
+}
+
+// Reset empties the buffer.
+func (r *RingBuffer) Reset() {
+	r.start = 0
+	r.size = 0
+}
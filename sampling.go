@@ -0,0 +1,167 @@
+package dsp
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Reservoir maintains a uniform random sample of fixed size over a stream of
+// unknown length (Algorithm R).
+type Reservoir struct {
+	k     int
+	seen  int
+	items []sampleItem
+	rng   *rand.Rand
+}
+
+// sampleItem is a sample retained by a reservoir along with its stream index.
+type sampleItem struct {
+	index int
+	value float64
+	key   float64
+}
+
+// NewReservoir creates a reservoir holding at most k samples. If rng is nil a
+// deterministic default source is used.
+func NewReservoir(k int, rng *rand.Rand) *Reservoir {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	return &Reservoir{k: k, items: make([]sampleItem, 0, k), rng: rng}
+}
+
+// Add offers a sample from the stream to the reservoir.
+func (r *Reservoir) Add(v float64) {
+	if len(r.items) < r.k {
+		r.items = append(r.items, sampleItem{index: r.seen, value: v})
+	} else if j := r.rng.Intn(r.seen + 1); j < r.k {
+		r.items[j] = sampleItem{index: r.seen, value: v}
+	}
+	r.seen++
+}
+
+// AddAll offers every sample in the data set to the reservoir.
+func (r *Reservoir) AddAll(d DataSet) {
+	for i := 0; i < len(d); i++ {
+		r.Add(d[i])
+	}
+}
+
+// Seen returns the number of samples offered to the reservoir.
+func (r *Reservoir) Seen() int {
+	return r.seen
+}
+
+// Sample returns the retained samples in stream order.
+func (r *Reservoir) Sample() DataSet {
+	return sampleValues(r.items)
+}
+
+// Indices returns the stream indices of the retained samples in stream order.
+func (r *Reservoir) Indices() []int {
+	return sampleIndices(r.items)
+}
+
+// WeightedReservoir maintains a weighted random sample without replacement of
+// fixed size over a stream (Efraimidis-Spirakis A-Res). Samples with larger
+// weights are proportionally more likely to be retained.
+type WeightedReservoir struct {
+	k    int
+	seen int
+	h    sampleHeap
+	rng  *rand.Rand
+}
+
+// NewWeightedReservoir creates a weighted reservoir holding at most k samples.
+// If rng is nil a deterministic default source is used.
+func NewWeightedReservoir(k int, rng *rand.Rand) *WeightedReservoir {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	return &WeightedReservoir{k: k, h: make(sampleHeap, 0, k), rng: rng}
+}
+
+// Add offers a sample with the given weight to the reservoir. Samples with a
+// non-positive weight are never retained.
+func (r *WeightedReservoir) Add(v, weight float64) {
+	index := r.seen
+	r.seen++
+	if weight <= 0 || r.k <= 0 {
+		return
+	}
+	key := math.Pow(r.rng.Float64(), 1/weight)
+	if len(r.h) < r.k {
+		heap.Push(&r.h, sampleItem{index: index, value: v, key: key})
+	} else if key > r.h[0].key {
+		r.h[0] = sampleItem{index: index, value: v, key: key}
+		heap.Fix(&r.h, 0)
+	}
+}
+
+// Seen returns the number of samples offered to the reservoir.
+func (r *WeightedReservoir) Seen() int {
+	return r.seen
+}
+
+// Sample returns the retained samples in stream order.
+func (r *WeightedReservoir) Sample() DataSet {
+	return sampleValues(r.h)
+}
+
+// Indices returns the stream indices of the retained samples in stream order.
+func (r *WeightedReservoir) Indices() []int {
+	return sampleIndices(r.h)
+}
+
+// ReservoirSample returns a uniform random sample of k points from the data set
+// in their original order.
+func (d DataSet) ReservoirSample(k int, rng *rand.Rand) DataSet {
+	r := NewReservoir(k, rng)
+	r.AddAll(d)
+	return r.Sample()
+}
+
+// sortedItems copies the items and sorts them by stream index.
+func sortedItems(items []sampleItem) []sampleItem {
+	s := make([]sampleItem, len(items))
+	copy(s, items)
+	sort.Slice(s, func(i, j int) bool { return s[i].index < s[j].index })
+	return s
+}
+
+// sampleValues returns the item values in stream order.
+func sampleValues(items []sampleItem) DataSet {
+	s := sortedItems(items)
+	values := make([]float64, len(s))
+	for i := 0; i < len(s); i++ {
+		values[i] = s[i].value
+	}
+	return values
+}
+
+// sampleIndices returns the item indices in stream order.
+func sampleIndices(items []sampleItem) []int {
+	s := sortedItems(items)
+	indices := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		indices[i] = s[i].index
+	}
+	return indices
+}
+
+// sampleHeap is a min-heap of items ordered by key.
+type sampleHeap []sampleItem
+
+func (h sampleHeap) Len() int            { return len(h) }
+func (h sampleHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h sampleHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *sampleHeap) Push(x interface{}) { *h = append(*h, x.(sampleItem)) }
+func (h *sampleHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,95 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// SlidingDFT tracks a set of DFT bins over a sliding window, updating each
+// bin in O(1) per incoming sample.
+type SlidingDFT struct {
+	n       int
+	bins    []int
+	twiddle []complex128
+	values  []complex128
+	history []float64
+	pos     int
+}
+
+// NewSlidingDFT creates a sliding DFT over a window of n samples which tracks
+// the given bin indices.
+func NewSlidingDFT(n int, bins ...int) *SlidingDFT {
+	twiddle := make([]complex128, len(bins))
+	for i, k := range bins {
+		twiddle[i] = cmplx.Rect(1, 2*math.Pi*float64(k)/float64(n))
+	}
+	return &SlidingDFT{
+		n:       n,
+		bins:    bins,
+		twiddle: twiddle,
+		values:  make([]complex128, len(bins)),
+		history: make([]float64, n),
+	}
+}
+
+// NewSlidingDFTFreqs creates a sliding DFT over a window of n samples which
+// tracks the bins nearest to the given frequencies.
+func NewSlidingDFTFreqs(n int, fS float64, freqs ...float64) *SlidingDFT {
+	bins := make([]int, len(freqs))
+	for i, f := range freqs {
+		bins[i] = int(math.Round(f * float64(n) / fS))
+	}
+	return NewSlidingDFT(n, bins...)
+}
+
+// Update adds a sample to the window and updates all the tracked bins.
+func (s *SlidingDFT) Update(x float64) {
+	delta := complex(x-s.history[s.pos], 0)
+	s.history[s.pos] = x
+	s.pos++
+	if s.pos == s.n {
+		s.pos = 0
+	}
+	for i := range s.values {
+		s.values[i] = (s.values[i] + delta) * s.twiddle[i]
+	}
+}
+
+// Process updates the sliding DFT with each sample in the block.
+func (s *SlidingDFT) Process(X []float64) {
+	for i := 0; i < len(X); i++ {
+		s.Update(X[i])
+	}
+}
+
+// Bins returns the indices of the tracked bins.
+func (s *SlidingDFT) Bins() []int {
+	return s.bins
+}
+
+// Values returns the current complex value of each tracked bin.
+func (s *SlidingDFT) Values() []complex128 {
+	values := make([]complex128, len(s.values))
+	copy(values, s.values)
+	return values
+}
+
+// Magnitudes returns the current magnitude of each tracked bin.
+func (s *SlidingDFT) Magnitudes() DataSet {
+	mags := make([]float64, len(s.values))
+	for i, v := range s.values {
+		mags[i] = cmplx.Abs(v)
+	}
+	return mags
+}
+
+// Reset clears the window and all the tracked bins.
+func (s *SlidingDFT) Reset() {
+	for i := range s.history {
+		s.history[i] = 0
+	}
+	for i := range s.values {
+		s.values[i] = 0
+	}
+	s.pos = 0
+}
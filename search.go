@@ -0,0 +1,204 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+)
+
+// searchChunk is the smallest FFT size used to scan a recording for a
+// template.
+const searchChunk = 1 << 14
+
+// Match is an occurrence of a template found in a recording.
+type Match struct {
+	// Index is the sample where the occurrence starts.
+	Index int
+
+	// Score is the normalized cross-correlation, between -1 and 1.
+	Score float64
+}
+
+// NormalizedCrossCorrelation returns the correlation coefficient between the
+// template and the data set at every position where the template fits
+// entirely, so the result has len(d)-len(template)+1 values. Each value lies
+// between -1 and 1 regardless of the local level and scale of the data, and
+// flat stretches of data score zero. The data is correlated in overlapping
+// blocks with FFTs sized to the template, so the working memory does not grow
+// with the length of the recording, though the result does; FindTemplate and
+// MappedData.FindTemplate keep only the matches.
+func (d DataSet) NormalizedCrossCorrelation(template DataSet) DataSet {
+	m := len(template)
+	if m == 0 || len(d) < m {
+		return DataSet{}
+	}
+	ncc := make(DataSet, len(d)-m+1)
+	newTemplateScanner(template).scan(len(d), func(start, end int) (DataSet, error) {
+		return d[start:end], nil
+	}, func(i int, v float64) {
+		ncc[i] = v
+	})
+	return ncc
+}
+
+// FindTemplate searches the data set for occurrences of the template and
+// returns up to count matches scoring at least threshold, best first. All
+// matches are returned if count is not positive. Matches are peaks of the
+// normalized cross-correlation and no two overlap: a weaker peak within a
+// template length of a stronger one is dropped.
+func (d DataSet) FindTemplate(template DataSet, threshold float64, count int) []Match {
+	matches, _ := findTemplate(len(d), template, threshold, count, func(start, end int) (DataSet, error) {
+		return d[start:end], nil
+	})
+	return matches
+}
+
+// FindTemplate searches the file for occurrences of the template in the same
+// way as DataSet.FindTemplate. The file is read a block at a time and only
+// the candidate peaks are kept, so recordings far larger than memory can be
+// searched. It stops at the first error reading the file.
+func (m *MappedData) FindTemplate(template DataSet, threshold float64, count int) ([]Match, error) {
+	var buf DataSet
+	return findTemplate(m.n, template, threshold, count, func(start, end int) (DataSet, error) {
+		if cap(buf) < end-start {
+			buf = make(DataSet, end-start)
+		}
+		block := buf[:end-start]
+		return block, m.decodeInto(block, start)
+	})
+}
+
+// findTemplate scans n samples, read a block at a time, for peaks of the
+// normalized cross-correlation with the template and ranks them.
+func findTemplate(n int, template DataSet, threshold float64, count int, read func(start, end int) (DataSet, error)) ([]Match, error) {
+	m := len(template)
+	if m == 0 || n < m {
+		return nil, nil
+	}
+
+	// a value is a peak once the one after it is known, so the scan runs a
+	// sample behind and the last value is checked at the end
+	var candidates []Match
+	prev, last := math.Inf(-1), math.Inf(-1)
+	peak := func(i int, next float64) {
+		if last >= threshold && prev <= last && next < last {
+			candidates = append(candidates, Match{Index: i, Score: last})
+		}
+	}
+	err := newTemplateScanner(template).scan(n, read, func(i int, v float64) {
+		if i > 0 {
+			peak(i-1, v)
+		}
+		prev, last = last, v
+	})
+	if err != nil {
+		return nil, err
+	}
+	peak(n-m, math.Inf(-1))
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	var matches []Match
+	var taken []int
+	for _, c := range candidates {
+		if count > 0 && len(matches) == count {
+			break
+		}
+		// taken is kept sorted, so only the neighbours can overlap
+		k := sort.SearchInts(taken, c.Index)
+		if k < len(taken) && taken[k]-c.Index < m {
+			continue
+		}
+		if k > 0 && c.Index-taken[k-1] < m {
+			continue
+		}
+		taken = append(taken, 0)
+		copy(taken[k+1:], taken[k:])
+		taken[k] = c.Index
+		matches = append(matches, c)
+	}
+	return matches, nil
+}
+
+// templateScanner correlates a template with a recording a block at a time.
+type templateScanner struct {
+	m     int
+	tNorm float64
+	plan  *FFTPlan
+	h     []complex128
+	buf   []complex128
+}
+
+// newTemplateScanner prepares the zero mean, time reversed template spectrum.
+func newTemplateScanner(template DataSet) *templateScanner {
+	m := len(template)
+	n := maxInt(searchChunk, NextPow2(4*m))
+	s := &templateScanner{m: m, plan: NewFFTPlan(n), h: make([]complex128, n), buf: make([]complex128, n)}
+
+	// a zero mean template makes the correlation ignore the local mean
+	mean := template.Mean()
+	var tss float64
+	for _, v := range template {
+		tss += (v - mean) * (v - mean)
+	}
+	s.tNorm = math.Sqrt(tss)
+	for i, v := range template {
+		s.h[m-1-i] = complex(v-mean, 0)
+	}
+	s.plan.Forward(s.h, s.h)
+	return s
+}
+
+// scan reads n samples in overlapping blocks of up to one FFT and calls fn
+// with the correlation at each of the n-m+1 positions in order.
+func (s *templateScanner) scan(n int, read func(start, end int) (DataSet, error), fn func(i int, v float64)) error {
+	m := s.m
+	step := len(s.buf) - m + 1
+	for start := 0; start < n-m+1; start += step {
+		d, err := read(start, minInt(start+len(s.buf), n))
+		if err != nil {
+			return err
+		}
+		positions := minInt(step, len(d)-m+1)
+		if s.tNorm == 0 {
+			for j := 0; j < positions; j++ {
+				fn(start+j, 0)
+			}
+			continue
+		}
+
+		buf := s.buf
+		for i := range buf {
+			buf[i] = 0
+			if i < len(d) {
+				buf[i] = complex(d[i], 0)
+			}
+		}
+		s.plan.Forward(buf, buf)
+		for i := range buf {
+			buf[i] *= s.h[i]
+		}
+		s.plan.Inverse(buf, buf)
+
+		// running sums give the energy of each window about its mean
+		var sum, ssq float64
+		for i := 0; i < m-1; i++ {
+			sum += d[i]
+			ssq += d[i] * d[i]
+		}
+		for j := 0; j < positions; j++ {
+			in := d[j+m-1]
+			sum += in
+			ssq += in * in
+			var v float64
+			if variance := ssq - sum*sum/float64(m); variance > 1e-12*ssq {
+				v = real(buf[j+m-1]) / (s.tNorm * math.Sqrt(variance))
+			}
+			fn(start+j, v)
+			out := d[j]
+			sum -= out
+			ssq -= out * out
+		}
+	}
+	return nil
+}
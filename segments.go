@@ -0,0 +1,83 @@
+package dsp
+
+import "log"
+
+// Segments is an ensemble of equal length data sets, such as repeated trials
+// or trigger-aligned epochs, whose statistics are taken point by point across
+// the ensemble.
+type Segments []DataSet
+
+// Segments cuts the data set into segments of length samples starting at each
+// index in starts. Segments running past either end of the data are skipped.
+func (d DataSet) Segments(starts []int, length int) Segments {
+	var segs Segments
+	for _, s := range starts {
+		if s < 0 || s+length > len(d) {
+			continue
+		}
+		seg := make([]float64, length)
+		copy(seg, d[s:s+length])
+		segs = append(segs, seg)
+	}
+	return segs
+}
+
+// Len returns the number of segments.
+func (s Segments) Len() int {
+	return len(s)
+}
+
+// Length returns the number of samples in each segment.
+func (s Segments) Length() int {
+	if len(s) == 0 {
+		return 0
+	}
+	n := len(s[0])
+	for _, seg := range s {
+		if len(seg) != n {
+			log.Fatal("Segments requires data sets of equal length")
+		}
+	}
+	return n
+}
+
+// At returns the values of every segment at sample i.
+func (s Segments) At(i int) DataSet {
+	values := make([]float64, len(s))
+	for j, seg := range s {
+		values[j] = seg[i]
+	}
+	return values
+}
+
+// Mean returns the pointwise mean across the segments.
+func (s Segments) Mean() DataSet {
+	return s.pointwise(DataSet.Mean)
+}
+
+// Stdev returns the pointwise standard deviation across the segments.
+func (s Segments) Stdev() DataSet {
+	return s.pointwise(DataSet.Stdev)
+}
+
+// Median returns the pointwise median across the segments.
+func (s Segments) Median() DataSet {
+	return s.Percentile(50)
+}
+
+// Percentile returns the pointwise p-th percentile (0 to 100) across the
+// segments.
+func (s Segments) Percentile(p float64) DataSet {
+	return s.pointwise(func(d DataSet) float64 {
+		return d.Percentile(p)
+	})
+}
+
+// pointwise applies fn to the values of every segment at each sample.
+func (s Segments) pointwise(fn func(DataSet) float64) DataSet {
+	values := make([]float64, s.Length())
+	for i := range values {
+		values[i] = fn(s.At(i))
+	}
+	return values
+}
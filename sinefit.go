@@ -0,0 +1,124 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// four parameter fit iteration limits
+const (
+	sineFitIterations = 50
+	sineFitTolerance  = 1e-12
+)
+
+// SineFit holds the parameters of the model
+// x(t) = Amplitude cos(2 pi Frequency t + Phase) + Offset
+// fitted to a record, along with the RMS residual of the fit.
+type SineFit struct {
+	Amplitude float64
+	Frequency float64
+	Phase     float64
+	Offset    float64
+	RMSError  float64
+}
+
+// FitSine3 fits a sine wave of known frequency to the data set by linear least
+// squares (the IEEE 1057 three parameter fit).
+func (d DataSet) FitSine3(freq, fS float64) SineFit {
+	w := 2 * math.Pi * freq / fS
+	rows := make([][]float64, len(d))
+	for i := range d {
+		s, c := math.Sincos(w * float64(i))
+		rows[i] = []float64{c, s, 1}
+	}
+	p := leastSquares(rows, d, 0)
+	if p == nil {
+		return SineFit{Frequency: freq}
+	}
+	return d.sineFit(p[0], p[1], p[2], freq, fS)
+}
+
+// FitSine4 fits a sine wave of unknown frequency to the data set with the
+// iterative IEEE 1057 four parameter fit. The frequency is refined starting
+// from the given estimate; if it is not positive the largest FFT peak is used.
+func (d DataSet) FitSine4(freq, fS float64) SineFit {
+	if freq <= 0 {
+		freq = d.peakFrequency(fS)
+	}
+	fit := d.FitSine3(freq, fS)
+	a := fit.Amplitude * math.Cos(fit.Phase)
+	b := -fit.Amplitude * math.Sin(fit.Phase)
+	c := fit.Offset
+	w := 2 * math.Pi * freq / fS
+
+	rows := make([][]float64, len(d))
+	for iter := 0; iter < sineFitIterations; iter++ {
+		for i := range d {
+			t := float64(i)
+			s, co := math.Sincos(w * t)
+			rows[i] = []float64{co, s, 1, t * (b*co - a*s)}
+		}
+		p := leastSquares(rows, d, 0)
+		if p == nil {
+			break
+		}
+		a, b, c = p[0], p[1], p[2]
+		w += p[3]
+		if math.Abs(p[3]) < sineFitTolerance*math.Abs(w) {
+			break
+		}
+	}
+	return d.sineFit(a, b, c, w*fS/(2*math.Pi), fS)
+}
+
+// sineFit converts the fitted cosine and sine coefficients into a SineFit and
+// computes the residual.
+func (d DataSet) sineFit(a, b, c, freq, fS float64) SineFit {
+	fit := SineFit{
+		Amplitude: math.Hypot(a, b),
+		Frequency: freq,
+		Phase:     math.Atan2(-b, a),
+		Offset:    c,
+	}
+	w := 2 * math.Pi * freq / fS
+	var ssq float64
+	for i := range d {
+		s, co := math.Sincos(w * float64(i))
+		r := d[i] - (a*co + b*s + c)
+		ssq += r * r
+	}
+	if len(d) > 0 {
+		fit.RMSError = math.Sqrt(ssq / float64(len(d)))
+	}
+	return fit
+}
+
+// peakFrequency returns the frequency of the largest non-DC FFT bin, refined
+// by parabolic interpolation of the log magnitude.
+func (d DataSet) peakFrequency(fS float64) float64 {
+	n := NextPow2(len(d))
+	mean := d.Mean()
+	window := Hann(len(d))
+	X := make([]complex128, n)
+	for i := range d {
+		X[i] = complex((d[i]-mean)*window[i], 0)
+	}
+	fft(X, false)
+
+	best := 1
+	for k := 1; k < n/2; k++ {
+		if cmplx.Abs(X[k]) > cmplx.Abs(X[best]) {
+			best = k
+		}
+	}
+	offset := 0.0
+	if best > 0 && best < n/2 {
+		l := math.Log(cmplx.Abs(X[best-1]) + 1e-300)
+		m := math.Log(cmplx.Abs(X[best]) + 1e-300)
+		r := math.Log(cmplx.Abs(X[best+1]) + 1e-300)
+		if den := l - 2*m + r; den != 0 {
+			offset = 0.5 * (l - r) / den
+		}
+	}
+	return (float64(best) + offset) * fS / float64(n)
+}
@@ -0,0 +1,206 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// SOS is a cascade of second-order sections. Running a high order filter as
+// a chain of biquads keeps it numerically stable where a single direct form
+// polynomial would not be.
+type SOS []Filter
+
+// Filter executes each section in turn on the given data.
+func (s SOS) Filter(X []float64) []float64 {
+	Y := X
+	for _, section := range s {
+		Y = section.Filter(Y)
+	}
+	if len(s) == 0 {
+		Y = make([]float64, len(X))
+		copy(Y, X)
+	}
+	return Y
+}
+
+// Response returns the complex frequency response of the cascade at freq.
+func (s SOS) Response(freq, fS float64) complex128 {
+	h := complex(1, 0)
+	for _, section := range s {
+		h *= section.Response(freq, fS)
+	}
+	return h
+}
+
+// TransferFunction multiplies out the sections into a single filter.
+func (s SOS) TransferFunction() *Filter {
+	num, den := []float64{1}, []float64{1}
+	for _, section := range s {
+		a, b := normalizeTF(section)
+		num = polyMul(num, a)
+		den = polyMul(den, b)
+	}
+	return &Filter{B: den, A: num}
+}
+
+// SOS factors the filter into a cascade of second-order sections by finding
+// the roots of its numerator and denominator.
+func (f Filter) SOS() SOS {
+	num, den := normalizeTF(f)
+
+	// leading zeros in the numerator are a pure delay
+	delay := 0
+	for delay < len(num) && num[delay] == 0 {
+		delay++
+	}
+	if delay == len(num) {
+		return SOS{{B: []float64{1, 0, 0}, A: []float64{0, 0, 0}}}
+	}
+
+	sos := FilterFromPZK(polyRoots(num[delay:]), polyRoots(den), num[delay])
+	for delay > 0 {
+		shifted := false
+		for i := range sos {
+			if sos[i].A[2] == 0 {
+				sos[i].A = []float64{0, sos[i].A[0], sos[i].A[1]}
+				shifted = true
+				break
+			}
+		}
+		if !shifted {
+			sos = append(sos, Filter{B: []float64{1, 0, 0}, A: []float64{0, 1, 0}})
+		}
+		delay--
+	}
+	return sos
+}
+
+// FilterFromPZK creates a digital filter from its zeros, poles and gain in the
+// z-plane. Complex zeros and poles must come in conjugate pairs. The roots are
+// grouped into second-order sections, ordered with the poles closest to the
+// unit circle last, and each pole pair is matched with its nearest zeros.
+func FilterFromPZK(zeros, poles []complex128, gain float64) SOS {
+	pp := pairRoots(poles)
+	zz := pairRoots(zeros)
+
+	// sections whose poles are nearest the unit circle have the most gain
+	// and go last so earlier sections do not clip
+	sort.SliceStable(pp, func(i, j int) bool {
+		return maxRadius(pp[i]) < maxRadius(pp[j])
+	})
+
+	var sos SOS
+	for _, p := range pp {
+		var z []complex128
+		if len(zz) > 0 {
+			best := 0
+			for i := range zz {
+				if rootDistance(zz[i], p) < rootDistance(zz[best], p) {
+					best = i
+				}
+			}
+			z = zz[best]
+			zz = append(zz[:best], zz[best+1:]...)
+		}
+		sos = append(sos, Filter{B: rootPoly(p), A: rootPoly(z)})
+	}
+	for _, z := range zz {
+		sos = append(sos, Filter{B: rootPoly(nil), A: rootPoly(z)})
+	}
+
+	if len(sos) == 0 {
+		return SOS{{B: []float64{1, 0, 0}, A: []float64{gain, 0, 0}}}
+	}
+	for i := range sos[0].A {
+		sos[0].A[i] *= gain
+	}
+	return sos
+}
+
+// pairRoots groups roots into conjugate pairs and pairs of real roots, with at
+// most one real root left on its own.
+func pairRoots(roots []complex128) [][]complex128 {
+	const tol = 1e-9
+
+	var reals []float64
+	var complexRoots []complex128
+	for _, r := range roots {
+		if math.Abs(imag(r)) <= tol*math.Max(1, cmplx.Abs(r)) {
+			reals = append(reals, real(r))
+		} else {
+			complexRoots = append(complexRoots, r)
+		}
+	}
+
+	var groups [][]complex128
+	used := make([]bool, len(complexRoots))
+	for i, r := range complexRoots {
+		if used[i] || imag(r) < 0 {
+			continue
+		}
+		match := -1
+		for j, c := range complexRoots {
+			if !used[j] && imag(c) < 0 && cmplx.Abs(c-cmplx.Conj(r)) <= tol*math.Max(1, cmplx.Abs(r)) {
+				match = j
+				break
+			}
+		}
+		if match < 0 {
+			continue
+		}
+		used[i], used[match] = true, true
+		groups = append(groups, []complex128{r, complexRoots[match]})
+	}
+	for i, r := range complexRoots {
+		if !used[i] {
+			log.Fatalf("FilterFromPZK requires complex roots in conjugate pairs, %v has no conjugate", r)
+		}
+	}
+
+	sort.Float64s(reals)
+	for i := 0; i < len(reals); i += 2 {
+		if i+1 < len(reals) {
+			groups = append(groups, []complex128{complex(reals[i], 0), complex(reals[i+1], 0)})
+		} else {
+			groups = append(groups, []complex128{complex(reals[i], 0)})
+		}
+	}
+	return groups
+}
+
+// rootPoly returns the second-order polynomial coefficients, in powers of
+// z^-1, with the given roots.
+func rootPoly(roots []complex128) []float64 {
+	switch len(roots) {
+	case 1:
+		return []float64{1, -real(roots[0]), 0}
+	case 2:
+		sum := roots[0] + roots[1]
+		prod := roots[0] * roots[1]
+		return []float64{1, -real(sum), real(prod)}
+	}
+	return []float64{1, 0, 0}
+}
+
+// maxRadius returns the largest magnitude of the roots.
+func maxRadius(roots []complex128) float64 {
+	var r float64
+	for _, v := range roots {
+		r = math.Max(r, cmplx.Abs(v))
+	}
+	return r
+}
+
+// rootDistance returns the smallest distance between any root of a and any
+// root of b.
+func rootDistance(a, b []complex128) float64 {
+	d := math.Inf(1)
+	for _, x := range a {
+		for _, y := range b {
+			d = math.Min(d, cmplx.Abs(x-y))
+		}
+	}
+	return d
+}
@@ -0,0 +1,211 @@
+package dsp
+
+import "math/cmplx"
+
+// segmentSpectra splits the signal into overlapping segments, removes the
+// mean of each segment, applies the window and returns the one-sided FFT of
+// each segment.
+func segmentSpectra(x DataSet, segLen, overlap int, window []float64) [][]complex128 {
+	step := segLen - overlap
+	if step <= 0 {
+		step = 1
+	}
+	nfft := NextPow2(segLen)
+	var spectra [][]complex128
+	for start := 0; start+segLen <= len(x); start += step {
+		seg := x[start : start+segLen]
+		mean := seg.Mean()
+		X := make([]complex128, nfft)
+		for i := 0; i < segLen; i++ {
+			X[i] = complex((seg[i]-mean)*window[i], 0)
+		}
+		fft(X, false)
+		spectra = append(spectra, X[:nfft/2+1])
+	}
+	return spectra
+}
+
+// crossSpectra estimates the averaged one-sided auto and cross power spectral
+// densities of x and y using Welch's method.
+func crossSpectra(x, y DataSet, segLen, overlap int, window []float64, fS float64) (DataSet, DataSet, []complex128) {
+	X := segmentSpectra(x, segLen, overlap, window)
+	Y := segmentSpectra(y, segLen, overlap, window)
+	nfft := NextPow2(segLen)
+	bins := nfft/2 + 1
+
+	sxx := make([]float64, bins)
+	syy := make([]float64, bins)
+	sxy := make([]complex128, bins)
+	segments := len(X)
+	if len(Y) < segments {
+		segments = len(Y)
+	}
+	for s := 0; s < segments; s++ {
+		for k := 0; k < bins; k++ {
+			sxx[k] += real(X[s][k] * cmplx.Conj(X[s][k]))
+			syy[k] += real(Y[s][k] * cmplx.Conj(Y[s][k]))
+			sxy[k] += cmplx.Conj(X[s][k]) * Y[s][k]
+		}
+	}
+
+	var wss float64
+	for _, v := range window {
+		wss += v * v
+	}
+	for k := 0; k < bins; k++ {
+		scale := 2 / (fS * wss * float64(segments))
+		if k == 0 || k == nfft/2 {
+			scale /= 2
+		}
+		sxx[k] *= scale
+		syy[k] *= scale
+		sxy[k] *= complex(scale, 0)
+	}
+	return sxx, syy, sxy
+}
+
+// rfftFreqs returns the frequencies of the one-sided bins of an n point FFT.
+func rfftFreqs(n int, fS float64) DataSet {
+	freqs := make([]float64, n/2+1)
+	for k := range freqs {
+		freqs[k] = float64(k) * fS / float64(n)
+	}
+	return freqs
+}
+
+// FrequencyResponse is a frequency response function estimated from measured
+// input and output signals.
+type FrequencyResponse struct {
+	Freqs DataSet
+
+	// H1 = Sxy/Sxx minimizes the effect of noise on the output.
+	H1 []complex128
+
+	// H2 = Syy/Syx minimizes the effect of noise on the input.
+	H2 []complex128
+
+	// Coherence is the magnitude-squared coherence between input and output.
+	Coherence DataSet
+}
+
+// TransferFunction estimates the frequency response from input x to output y
+// using averaged cross and auto spectra over Hann windowed segments of segLen
+// samples overlapping by overlap samples.
+func TransferFunction(x, y DataSet, segLen, overlap int, fS float64) *FrequencyResponse {
+	sxx, syy, sxy := crossSpectra(x, y, segLen, overlap, Hann(segLen), fS)
+	frf := &FrequencyResponse{
+		Freqs:     rfftFreqs(NextPow2(segLen), fS),
+		H1:        make([]complex128, len(sxx)),
+		H2:        make([]complex128, len(sxx)),
+		Coherence: make([]float64, len(sxx)),
+	}
+	for k := range sxx {
+		frf.H1[k] = sxy[k] / complex(sxx[k], 0)
+		frf.H2[k] = complex(syy[k], 0) / cmplx.Conj(sxy[k])
+		a := cmplx.Abs(sxy[k])
+		frf.Coherence[k] = a * a / (sxx[k] * syy[k])
+	}
+	return frf
+}
+
+// Coherence estimates the magnitude-squared coherence between x and y, the
+// fraction of the power of y at each frequency that is linearly related to x.
+// It is 1 for a noiseless linear system and falls towards 0 as the channels
+// become unrelated. If window is nil, a Hann window is used. It returns the
+// bin frequencies and the coherence.
+func Coherence(x, y DataSet, segLen, overlap int, window DataSet, fS float64) (DataSet, DataSet) {
+	if window == nil {
+		window = Hann(segLen)
+	}
+	sxx, syy, sxy := crossSpectra(x, y, segLen, overlap, window, fS)
+	coh := make([]float64, len(sxx))
+	for k := range coh {
+		if d := sxx[k] * syy[k]; d > 0 {
+			a := cmplx.Abs(sxy[k])
+			coh[k] = a * a / d
+		}
+	}
+	return rfftFreqs(NextPow2(segLen), fS), coh
+}
+
+// WelchPSD estimates the one-sided power spectral density of the data set
+// using Welch's method: the mean of the periodograms of overlapping windowed
+// segments. Averaging trades frequency resolution for a lower variance than a
+// single periodogram. If window is nil, a Hann window is used. It returns the
+// bin frequencies and the density in units squared per Hz.
+func (d DataSet) WelchPSD(segmentLen, overlap int, window DataSet, fS float64) (DataSet, DataSet) {
+	if window == nil {
+		window = Hann(segmentLen)
+	}
+	nfft := NextPow2(segmentLen)
+	psd := make([]float64, nfft/2+1)
+	spectra := segmentSpectra(d, segmentLen, overlap, window)
+	if len(spectra) == 0 {
+		return rfftFreqs(nfft, fS), psd
+	}
+	for _, X := range spectra {
+		for k, v := range X {
+			psd[k] += real(v)*real(v) + imag(v)*imag(v)
+		}
+	}
+
+	var wss float64
+	for _, v := range window {
+		wss += v * v
+	}
+	for k := range psd {
+		scale := 2 / (fS * wss * float64(len(spectra)))
+		if k == 0 || k == nfft/2 {
+			scale /= 2
+		}
+		psd[k] *= scale
+	}
+	return rfftFreqs(nfft, fS), psd
+}
+
+// Scaling selects the units of a power spectrum.
+type Scaling int
+
+const (
+	// DensityScaling returns power spectral density in units squared per Hz,
+	// suited to broadband signals and noise.
+	DensityScaling Scaling = iota
+
+	// SpectrumScaling returns power in units squared per bin, so a sine that
+	// falls exactly on a bin reads its mean square value.
+	SpectrumScaling
+)
+
+// Periodogram returns the power spectrum of the data set from a single
+// unwindowed FFT of its full length. A one-sided result folds the negative
+// frequencies onto the bins 0 through N/2 of a real signal; a two-sided
+// result covers every bin in the layout of FFTFreqs.
+func (d DataSet) Periodogram(fS float64, scaling Scaling, onesided bool) (DataSet, DataSet) {
+	n := len(d)
+	if n == 0 {
+		return DataSet{}, DataSet{}
+	}
+	bins := d.FFT()
+	scale := 1 / (fS * float64(n))
+	if scaling == SpectrumScaling {
+		scale = 1 / float64(n*n)
+	}
+
+	if !onesided {
+		power := make([]float64, n)
+		for k, v := range bins {
+			power[k] = (real(v)*real(v) + imag(v)*imag(v)) * scale
+		}
+		return FFTFreqs(n, fS), power
+	}
+
+	power := make([]float64, n/2+1)
+	for k := range power {
+		v := bins[k]
+		power[k] = (real(v)*real(v) + imag(v)*imag(v)) * scale
+		if k != 0 && !(n%2 == 0 && k == n/2) {
+			power[k] *= 2
+		}
+	}
+	return rfftFreqs(n, fS), power
+}
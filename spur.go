@@ -0,0 +1,99 @@
+package dsp
+
+import (
+	"log"
+	"math"
+	"sort"
+)
+
+// Spur is a spurious tone found in a spectrum.
+type Spur struct {
+	// Bin is the spectrum bin of the spur peak.
+	Bin int
+
+	// Frequency is the frequency of the spur.
+	Frequency float64
+
+	// Level is the power of the spur relative to the carrier in dBc.
+	Level float64
+}
+
+// Spurs searches a one-sided power spectrum, with bin frequencies freqs, for
+// the count largest spurs. The carrier is taken as the largest bin. Bins
+// within exclude Hz of DC, the carrier and its harmonics up to the given
+// order, folded back below Nyquist where they alias, are skipped, as is the
+// spread of each of them due to the analysis window. Spurs are local peaks of
+// the remaining bins, reported strongest first with levels in dBc. The
+// spread of a tone is taken as the bins falling away from it on either side
+// down to the first local minimum. All spurs are returned if count is not
+// positive.
+func (d DataSet) Spurs(freqs DataSet, harmonics int, exclude float64, count int) []Spur {
+	if len(d) < 3 {
+		return nil
+	}
+	carrier := 0
+	for i, v := range d {
+		if v > d[carrier] {
+			carrier = i
+		}
+	}
+
+	nyquist := freqs[len(freqs)-1]
+	excluded := []float64{0}
+	for h := 1; h <= maxInt(harmonics, 1); h++ {
+		f := math.Mod(float64(h)*freqs[carrier], 2*nyquist)
+		if f > nyquist {
+			f = 2*nyquist - f
+		}
+		excluded = append(excluded, f)
+	}
+	skip := make([]bool, len(d))
+	for _, e := range excluded {
+		c := 0
+		for i := range d {
+			if math.Abs(freqs[i]-e) < math.Abs(freqs[c]-e) {
+				c = i
+			}
+		}
+		lo, hi := c, c
+		for lo > 0 && (math.Abs(freqs[lo-1]-e) <= exclude || d[lo-1] < d[lo]) {
+			lo--
+		}
+		for hi < len(d)-1 && (math.Abs(freqs[hi+1]-e) <= exclude || d[hi+1] < d[hi]) {
+			hi++
+		}
+		for i := lo; i <= hi; i++ {
+			skip[i] = true
+		}
+	}
+
+	var spurs []Spur
+	for i := 1; i < len(d)-1; i++ {
+		if d[i] <= d[i-1] || d[i] < d[i+1] || d[i] <= 0 || skip[i] {
+			continue
+		}
+		spurs = append(spurs, Spur{
+			Bin:       i,
+			Frequency: freqs[i],
+			Level:     10 * math.Log10(d[i]/d[carrier]),
+		})
+	}
+	sort.SliceStable(spurs, func(i, j int) bool {
+		return spurs[i].Level > spurs[j].Level
+	})
+	if count > 0 && len(spurs) > count {
+		spurs = spurs[:count]
+	}
+	return spurs
+}
+
+// NoiseFigure returns the noise figure in dB measured with the Y-factor
+// method, from the noise source excess noise ratio in dB and the output
+// noise powers measured with the source hot (on) and cold (off).
+func NoiseFigure(enrDB, hot, cold float64) float64 {
+	if cold <= 0 || hot <= cold {
+		log.Fatalf("NoiseFigure requires hot > cold > 0, got hot %v and cold %v", hot, cold)
+	}
+	y := hot / cold
+	return 10 * math.Log10(math.Pow(10, enrDB/10)/(y-1))
+}
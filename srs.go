@@ -0,0 +1,87 @@
+package dsp
+
+import "math"
+
+// ShockSpectrum is a shock response spectrum: the peak absolute acceleration
+// response of a bank of single degree of freedom oscillators to a base input.
+type ShockSpectrum struct {
+	Freqs DataSet
+
+	// PosPrimary and NegPrimary are the largest positive and negative
+	// responses while the input is applied.
+	PosPrimary, NegPrimary DataSet
+
+	// PosResidual and NegResidual are the largest positive and negative
+	// responses after the input has ended.
+	PosResidual, NegResidual DataSet
+
+	// Maximax is the largest absolute response at any time.
+	Maximax DataSet
+}
+
+// OctaveGrid returns frequencies from fMin to fMax spaced by a fraction of an
+// octave, the standard grid for shock response spectra.
+func OctaveGrid(fMin, fMax float64, perOctave int) DataSet {
+	var freqs []float64
+	for i := 0; ; i++ {
+		f := fMin * math.Pow(2, float64(i)/float64(perOctave))
+		if f > fMax*(1+1e-9) {
+			break
+		}
+		freqs = append(freqs, f)
+	}
+	return freqs
+}
+
+// NewSDOFFilter creates the Smallwood ramp-invariant filter giving the
+// absolute acceleration response of a single degree of freedom oscillator
+// with natural frequency fN and quality factor Q to a base acceleration.
+func NewSDOFFilter(fN, Q, fS float64) *Filter {
+	zeta := 1 / (2 * Q)
+	A := 2 * math.Pi * fN / fS
+	E := math.Exp(-zeta * A)
+	K := A * math.Sqrt(1-zeta*zeta)
+	C := E * math.Cos(K)
+	S := E * math.Sin(K)
+	Sp := S / K
+
+	a0 := 1 - Sp
+	a1 := 2 * (Sp - C)
+	a2 := E*E - Sp
+	b0 := 1.0
+	b1 := -2 * C
+	b2 := E * E
+
+	return &Filter{[]float64{b0, b1, b2}, []float64{a0, a1, a2}}
+}
+
+// SRS computes the shock response spectrum of a base acceleration record at
+// each natural frequency with the given quality factor (10 is customary). The
+// residual response is taken over one full period after the record ends.
+func (d DataSet) SRS(fS float64, freqs DataSet, Q float64) *ShockSpectrum {
+	n := len(freqs)
+	srs := &ShockSpectrum{
+		Freqs:       freqs,
+		PosPrimary:  make([]float64, n),
+		NegPrimary:  make([]float64, n),
+		PosResidual: make([]float64, n),
+		NegResidual: make([]float64, n),
+		Maximax:     make([]float64, n),
+	}
+	for i, f := range freqs {
+		tail := int(math.Ceil(fS/f)) + 1
+		x := make([]float64, len(d)+tail)
+		copy(x, d)
+		y := NewSDOFFilter(f, Q, fS).Filter(x)
+
+		primary := DataSet(y[:len(d)])
+		residual := DataSet(y[len(d):])
+		srs.PosPrimary[i] = math.Max(0, primary.Max())
+		srs.NegPrimary[i] = math.Max(0, -primary.Min())
+		srs.PosResidual[i] = math.Max(0, residual.Max())
+		srs.NegResidual[i] = math.Max(0, -residual.Min())
+		srs.Maximax[i] = math.Max(math.Max(srs.PosPrimary[i], srs.NegPrimary[i]),
+			math.Max(srs.PosResidual[i], srs.NegResidual[i]))
+	}
+	return srs
+}
@@ -0,0 +1,195 @@
+package dsp
+
+// StateSpace is a single input, single output filter in state-space form,
+//
+//	x[n+1] = A x[n] + B u[n]
+//	y[n]   = C x[n] + D u[n]
+//
+// It keeps its state between calls to Process, so a signal can be filtered
+// block by block.
+type StateSpace struct {
+	A    [][]float64
+	B, C []float64
+	D    float64
+
+	state []float64
+}
+
+// StateSpace converts the filter to controllable canonical state-space form.
+func (f Filter) StateSpace() *StateSpace {
+	num, den := normalizeTF(f)
+	n := len(den) - 1
+
+	A := make([][]float64, n)
+	for i := range A {
+		A[i] = make([]float64, n)
+		if i > 0 {
+			A[i][i-1] = 1
+		}
+	}
+	B := make([]float64, n)
+	C := make([]float64, n)
+	for i := 0; i < n; i++ {
+		A[0][i] = -den[i+1]
+		C[i] = num[i+1] - num[0]*den[i+1]
+	}
+	if n > 0 {
+		B[0] = 1
+	}
+	return &StateSpace{A: A, B: B, C: C, D: num[0]}
+}
+
+// StateSpace converts the cascade to a single state-space system by
+// connecting the state-space form of each section in series.
+func (s SOS) StateSpace() *StateSpace {
+	sys := &StateSpace{D: 1}
+	for _, section := range s {
+		sys = sys.series(section.StateSpace())
+	}
+	return sys
+}
+
+// TransferFunction converts the system to a transfer function.
+func (s *StateSpace) TransferFunction() *Filter {
+	n := s.Order()
+	den := charPoly(s.A)
+
+	// the numerator is det(zI - A + BC) + (D - 1) det(zI - A)
+	ABC := make([][]float64, n)
+	for i := range ABC {
+		ABC[i] = make([]float64, n)
+		for j := range ABC[i] {
+			ABC[i][j] = s.A[i][j] - s.B[i]*s.C[j]
+		}
+	}
+	num := charPoly(ABC)
+	for i := range num {
+		num[i] += (s.D - 1) * den[i]
+	}
+	return &Filter{B: den, A: num}
+}
+
+// SOS converts the system to a cascade of second-order sections.
+func (s *StateSpace) SOS() SOS {
+	return s.TransferFunction().SOS()
+}
+
+// Order returns the number of states.
+func (s *StateSpace) Order() int {
+	return len(s.A)
+}
+
+// Process filters a block of samples, continuing from the state left by the
+// previous block.
+func (s *StateSpace) Process(X []float64) []float64 {
+	n := s.Order()
+	if len(s.state) != n {
+		s.state = make([]float64, n)
+	}
+	next := make([]float64, n)
+	Y := make([]float64, len(X))
+	for m, u := range X {
+		y := s.D * u
+		for i := 0; i < n; i++ {
+			y += s.C[i] * s.state[i]
+		}
+		for i := 0; i < n; i++ {
+			v := s.B[i] * u
+			for j := 0; j < n; j++ {
+				v += s.A[i][j] * s.state[j]
+			}
+			next[i] = v
+		}
+		s.state, next = next, s.state
+		Y[m] = y
+	}
+	return Y
+}
+
+// Reset clears the state.
+func (s *StateSpace) Reset() {
+	for i := range s.state {
+		s.state[i] = 0
+	}
+}
+
+// series returns the system that feeds the output of s into t.
+func (s *StateSpace) series(t *StateSpace) *StateSpace {
+	n1, n2 := s.Order(), t.Order()
+	n := n1 + n2
+	A := make([][]float64, n)
+	for i := range A {
+		A[i] = make([]float64, n)
+	}
+	B := make([]float64, n)
+	C := make([]float64, n)
+	for i := 0; i < n1; i++ {
+		copy(A[i][:n1], s.A[i])
+		B[i] = s.B[i]
+		C[i] = t.D * s.C[i]
+	}
+	for i := 0; i < n2; i++ {
+		for j := 0; j < n1; j++ {
+			A[n1+i][j] = t.B[i] * s.C[j]
+		}
+		copy(A[n1+i][n1:], t.A[i])
+		B[n1+i] = t.B[i] * s.D
+		C[n1+i] = t.C[i]
+	}
+	return &StateSpace{A: A, B: B, C: C, D: t.D * s.D}
+}
+
+// normalizeTF returns the numerator and denominator of the filter padded to
+// the same length and scaled so the denominator starts with 1.
+func normalizeTF(f Filter) ([]float64, []float64) {
+	n := maxInt(len(f.A), len(f.B))
+	num := make([]float64, n)
+	den := make([]float64, n)
+	copy(num, f.A)
+	copy(den, f.B)
+	if n > 0 && den[0] != 0 && den[0] != 1 {
+		g := den[0]
+		for i := range den {
+			num[i] /= g
+			den[i] /= g
+		}
+	}
+	return num, den
+}
+
+// charPoly returns the coefficients of det(zI - A), highest power first,
+// using the Faddeev-LeVerrier recurrence.
+func charPoly(A [][]float64) []float64 {
+	n := len(A)
+	c := make([]float64, n+1)
+	c[0] = 1
+	M := make([][]float64, n)
+	for i := range M {
+		M[i] = make([]float64, n)
+	}
+	for k := 1; k <= n; k++ {
+		// M = A M + c[k-1] I
+		next := make([][]float64, n)
+		for i := range next {
+			next[i] = make([]float64, n)
+			for j := 0; j < n; j++ {
+				var v float64
+				for l := 0; l < n; l++ {
+					v += A[i][l] * M[l][j]
+				}
+				next[i][j] = v
+			}
+			next[i][i] += c[k-1]
+		}
+		M = next
+
+		var trace float64
+		for i := 0; i < n; i++ {
+			for l := 0; l < n; l++ {
+				trace += A[i][l] * M[l][i]
+			}
+		}
+		c[k] = -trace / float64(k)
+	}
+	return c
+}
@@ -0,0 +1,128 @@
+package dsp
+
+import "math/cmplx"
+
+// Spectrogram holds the short-time Fourier transform of a signal. Data holds
+// one one-sided spectrum per frame; frame t is centered on sample t*Hop.
+type Spectrogram struct {
+	Data       [][]complex128
+	WindowSize int
+	Hop        int
+	Window     DataSet
+}
+
+// STFT computes the short-time Fourier transform of the data set using frames
+// of windowSize samples taken every hop samples. Frames are zero padded to a
+// power of two. If window is nil, a Hann window is used.
+func (d DataSet) STFT(windowSize, hop int, window DataSet) *Spectrogram {
+	if window == nil {
+		window = Hann(windowSize)
+	}
+	return &Spectrogram{
+		Data:       stft(d, windowSize, hop, window),
+		WindowSize: windowSize,
+		Hop:        hop,
+		Window:     window,
+	}
+}
+
+// Times returns the time in seconds at the center of each frame.
+func (s *Spectrogram) Times(fS float64) DataSet {
+	times := make([]float64, len(s.Data))
+	for t := range times {
+		times[t] = float64(t*s.Hop) / fS
+	}
+	return times
+}
+
+// Frequencies returns the frequency of each bin.
+func (s *Spectrogram) Frequencies(fS float64) DataSet {
+	return rfftFreqs(NextPow2(s.WindowSize), fS)
+}
+
+// Magnitude returns the magnitude of each bin, indexed by frame then bin.
+func (s *Spectrogram) Magnitude() [][]float64 {
+	mag := make([][]float64, len(s.Data))
+	for t, frame := range s.Data {
+		mag[t] = Magnitude(frame)
+	}
+	return mag
+}
+
+// Phase returns the phase of each bin in radians, indexed by frame then bin.
+func (s *Spectrogram) Phase() [][]float64 {
+	phase := make([][]float64, len(s.Data))
+	for t, frame := range s.Data {
+		phase[t] = Phase(frame)
+	}
+	return phase
+}
+
+// Inverse reconstructs a signal of the given length from the spectrogram.
+func (s *Spectrogram) Inverse(length int) DataSet {
+	return istft(s.Data, s.WindowSize, s.Hop, s.Window, length)
+}
+
+// stft returns the one-sided spectra of overlapping windowed frames of x. The
+// signal is padded by half a frame on each side so every sample is covered by
+// a full set of overlapping frames.
+func stft(x DataSet, size, hop int, window []float64) [][]complex128 {
+	nfft := NextPow2(size)
+	pad := size / 2
+	padded := make([]float64, len(x)+2*pad+size)
+	copy(padded[pad:], x)
+
+	var frames [][]complex128
+	for start := 0; start+size <= len(x)+2*pad; start += hop {
+		X := make([]complex128, nfft)
+		for i := 0; i < size; i++ {
+			X[i] = complex(padded[start+i]*window[i], 0)
+		}
+		fft(X, false)
+		frames = append(frames, X[:nfft/2+1])
+	}
+	return frames
+}
+
+// istft reconstructs a signal of the given length from one-sided frame spectra
+// produced by stft, using weighted overlap-add with the same window.
+func istft(frames [][]complex128, size, hop int, window []float64, length int) DataSet {
+	pad := size / 2
+	out := make([]float64, length+2*pad+size)
+	norm := make([]float64, len(out))
+	for f, half := range frames {
+		frame := irfft(half, NextPow2(size))
+		start := f * hop
+		for i := 0; i < size && start+i < len(out); i++ {
+			out[start+i] += frame[i] * window[i]
+			norm[start+i] += window[i] * window[i]
+		}
+	}
+
+	values := make([]float64, length)
+	for i := range values {
+		if n := norm[i+pad]; n > 1e-10 {
+			values[i] = out[i+pad] / n
+		}
+	}
+	return values
+}
+
+// irfft returns the real inverse FFT of length n from its one-sided spectrum.
+func irfft(half []complex128, n int) []float64 {
+	X := make([]complex128, n)
+	copy(X, half)
+	for k := n/2 + 1; k < n; k++ {
+		X[k] = cmplx.Conj(X[n-k])
+	}
+	if isPow2(n) {
+		fft(X, true)
+	} else {
+		X = dft(X, true)
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = real(X[i])
+	}
+	return values
+}
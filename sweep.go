@@ -0,0 +1,69 @@
+package dsp
+
+import "math"
+
+// ExpSweep generates an exponential (logarithmic) sine sweep from f1 to f2 Hz
+// lasting the given duration in seconds, as used for Farina impulse response
+// measurements.
+func ExpSweep(f1, f2, duration, fS float64) DataSet {
+	n := int(duration * fS)
+	R := math.Log(f2 / f1)
+	sweep := make([]float64, n)
+	for i := range sweep {
+		t := float64(i) / fS
+		sweep[i] = math.Sin(2 * math.Pi * f1 * duration / R * (math.Exp(t*R/duration) - 1))
+	}
+	return sweep
+}
+
+// InverseSweep returns the inverse filter of an exponential sweep: the time
+// reversed sweep with an amplitude envelope decaying 6 dB per octave to undo
+// the sweep's pink spectrum. It is scaled so that convolving it with the sweep
+// gives an impulse of unit height.
+func InverseSweep(f1, f2, duration, fS float64) DataSet {
+	sweep := ExpSweep(f1, f2, duration, fS)
+	n := len(sweep)
+	R := math.Log(f2 / f1)
+	inv := make([]float64, n)
+	for i := range inv {
+		t := float64(i) / fS
+		inv[i] = sweep[n-1-i] * math.Exp(-t*R/duration)
+	}
+
+	peak := DataSet(fftConvolve(sweep, inv)).Max()
+	for i := range inv {
+		inv[i] /= peak
+	}
+	return inv
+}
+
+// DeconvolveSweep recovers the impulse response of a system from its recorded
+// response to an exponential sweep by convolving with the inverse sweep. The
+// linear impulse response starts at index len(sweep)-1 of the result, while
+// the impulse responses of the harmonic distortion products appear earlier;
+// see HarmonicIRs.
+func DeconvolveSweep(recorded DataSet, f1, f2, duration, fS float64) DataSet {
+	return fftConvolve(recorded, InverseSweep(f1, f2, duration, fS))
+}
+
+// HarmonicIRs separates the linear impulse response and the impulse responses
+// of the 2nd through n-th harmonic distortion products from a deconvolved sweep
+// response. Harmonic k arrives T ln(k) / ln(f2/f1) seconds ahead of the linear
+// response; length samples are taken from each, which should be shorter than
+// the spacing between adjacent harmonics. The linear response is first.
+func HarmonicIRs(deconvolved DataSet, f1, f2, duration, fS float64, harmonics, length int) []DataSet {
+	linear := int(duration*fS) - 1
+	R := math.Log(f2 / f1)
+	irs := make([]DataSet, 0, harmonics)
+	for k := 1; k <= harmonics; k++ {
+		start := linear - int(math.Round(duration*math.Log(float64(k))/R*fS))
+		ir := make([]float64, length)
+		for i := range ir {
+			if j := start + i; j >= 0 && j < len(deconvolved) {
+				ir[i] = deconvolved[j]
+			}
+		}
+		irs = append(irs, ir)
+	}
+	return irs
+}
@@ -0,0 +1,60 @@
+package dsp
+
+// SynchronousAverage averages the segments of length samples that start at
+// each trigger, a fractional sample index such as those from RisingEdges.
+// Components locked to the trigger add coherently while noise averages
+// towards zero, improving the signal to noise ratio by the square root of the
+// number of segments. Segments running past the end of the data are skipped.
+//
+// Trigger jitter smears the average and attenuates high frequencies. If
+// maxShift is positive, each segment is realigned to the initial average by
+// cross-correlation within maxShift samples, refined to a fraction of a
+// sample, before averaging again. It returns the average and the correction
+// applied to each trigger used.
+func (d DataSet) SynchronousAverage(triggers DataSet, length, maxShift int) (DataSet, DataSet) {
+	var starts []float64
+	for _, t := range triggers {
+		if t >= 0 && t+float64(length-1) <= float64(len(d)-1) {
+			starts = append(starts, t)
+		}
+	}
+	shifts := make([]float64, len(starts))
+	avg := d.averageSegments(starts, shifts, length)
+	if maxShift <= 0 || len(starts) == 0 {
+		return avg, shifts
+	}
+
+	for s, t := range starts {
+		seg := d.segmentAt(t, length)
+		lag, _ := CorrelationLag(seg, avg, maxShift)
+		shifts[s] = lag
+	}
+	return d.averageSegments(starts, shifts, length), shifts
+}
+
+// averageSegments returns the mean of the segments starting at each start
+// plus its shift.
+func (d DataSet) averageSegments(starts, shifts []float64, length int) DataSet {
+	avg := make([]float64, length)
+	if len(starts) == 0 {
+		return avg
+	}
+	for s, t := range starts {
+		for i, v := range d.segmentAt(t+shifts[s], length) {
+			avg[i] += v
+		}
+	}
+	for i := range avg {
+		avg[i] /= float64(len(starts))
+	}
+	return avg
+}
+
+// segmentAt returns length samples starting at the fractional index t.
+func (d DataSet) segmentAt(t float64, length int) DataSet {
+	seg := make([]float64, length)
+	for i := range seg {
+		seg[i] = sampleAt(d, t+float64(i))
+	}
+	return seg
+}
@@ -0,0 +1,96 @@
+package dsp
+
+import "math"
+
+// sysidRidge is the relative ridge term used in system identification, just
+// enough to keep the normal equations solvable for poorly exciting inputs.
+const sysidRidge = 1e-10
+
+// ModelFit describes how well an identified model reproduces measured data.
+type ModelFit struct {
+	// Fit is the normalized root mean square fit of the simulated output in
+	// percent, 100 (1 - |y - yhat| / |y - mean(y)|). A perfect model scores
+	// 100 and a model no better than the mean scores 0 or less.
+	Fit float64
+
+	// PredictionError is the mean square one-step-ahead prediction error,
+	// an estimate of the noise variance.
+	PredictionError float64
+}
+
+// IdentifyFIR estimates an FIR model with the given number of taps from a
+// record of the input and output of a system by linear least squares. The
+// input should excite every frequency of interest, for instance white noise
+// or a sweep.
+func IdentifyFIR(input, output DataSet, taps int) (*Filter, ModelFit) {
+	return IdentifyARX(input, output, 0, taps, 0)
+}
+
+// IdentifyARX estimates the ARX model
+//
+//	y[n] + a1 y[n-1] + ... + a_na y[n-na] = b0 u[n-d] + ... + b_(nb-1) u[n-d-nb+1]
+//
+// from a record of the input u and output y of a system by linear least
+// squares, where d is the input delay in samples. It returns the model as a
+// filter along with its fit to the record.
+func IdentifyARX(input, output DataSet, na, nb, delay int) (*Filter, ModelFit) {
+	checkLengths("IdentifyARX", input, output)
+	start := maxInt(na, delay+nb-1)
+
+	var rows [][]float64
+	var targets []float64
+	for n := start; n < len(output); n++ {
+		row := make([]float64, na+nb)
+		for i := 1; i <= na; i++ {
+			row[i-1] = -output[n-i]
+		}
+		for j := 0; j < nb; j++ {
+			row[na+j] = input[n-delay-j]
+		}
+		rows = append(rows, row)
+		targets = append(targets, output[n])
+	}
+	theta := leastSquares(rows, targets, sysidRidge)
+	if theta == nil {
+		theta = make([]float64, na+nb)
+	}
+
+	size := maxInt(na+1, delay+nb)
+	den := make([]float64, size)
+	num := make([]float64, size)
+	den[0] = 1
+	copy(den[1:], theta[:na])
+	copy(num[delay:], theta[na:])
+	model := &Filter{B: den, A: num}
+
+	var fit ModelFit
+	if len(rows) > 0 {
+		for r, row := range rows {
+			e := targets[r]
+			for i, c := range theta {
+				e -= c * row[i]
+			}
+			fit.PredictionError += e * e
+		}
+		fit.PredictionError /= float64(len(rows))
+	}
+	fit.Fit = nrmseFit(output, model.Filter(input))
+	return model, fit
+}
+
+// nrmseFit returns the normalized root mean square fit of yhat to y in
+// percent.
+func nrmseFit(y, yhat DataSet) float64 {
+	mean := y.Mean()
+	var err, dev float64
+	for i, v := range y {
+		e := v - yhat[i]
+		d := v - mean
+		err += e * e
+		dev += d * d
+	}
+	if dev == 0 {
+		return 0
+	}
+	return 100 * (1 - math.Sqrt(err/dev))
+}
@@ -0,0 +1,101 @@
+package dsp
+
+import "log"
+
+// Tensor is a dense array of float32 values in row-major order, the layout
+// exchanged with inference runtimes such as ONNX Runtime.
+type Tensor struct {
+	Shape []int
+	Data  []float32
+}
+
+// InferenceFunc runs a model on a batch of frames, a tensor of shape
+// [batch, frame size], and returns its output with the batch as the first
+// dimension.
+type InferenceFunc func(in *Tensor) (*Tensor, error)
+
+// TensorStage is a Processor that splices a model into a Pipeline. Samples are
+// collected into frames of FrameSize, and frames into batches of up to
+// BatchSize, which are converted to a tensor and passed to the model. The
+// output tensor is flattened back into samples, so a denoising model that
+// returns frames of the same size streams audio through, while a classifier
+// emits its scores for each frame in turn. Output lags input by up to a
+// batch.
+type TensorStage struct {
+	FrameSize int
+	BatchSize int
+	Model     InferenceFunc
+
+	pending []float64
+	err     error
+}
+
+// NewTensorStage creates a model stage with the given frame and batch sizes.
+func NewTensorStage(frameSize, batchSize int, model InferenceFunc) *TensorStage {
+	if frameSize <= 0 {
+		log.Fatalf("NewTensorStage requires a positive frame size, got %d", frameSize)
+	}
+	return &TensorStage{FrameSize: frameSize, BatchSize: batchSize, Model: model}
+}
+
+// Process buffers a block of samples and returns the model output for every
+// complete batch. After the model fails no more output is produced; see Err.
+func (s *TensorStage) Process(X []float64) []float64 {
+	s.pending = append(s.pending, X...)
+	batch := s.FrameSize * maxInt(s.BatchSize, 1)
+	var out []float64
+	for len(s.pending) >= batch {
+		out = append(out, s.run(s.pending[:batch])...)
+		s.pending = s.pending[batch:]
+	}
+	return out
+}
+
+// Flush runs the model on the complete frames left over from Process, in a
+// final smaller batch, and returns the output. Samples short of a whole frame
+// are discarded.
+func (s *TensorStage) Flush() []float64 {
+	n := len(s.pending) / s.FrameSize * s.FrameSize
+	var out []float64
+	if n > 0 {
+		out = s.run(s.pending[:n])
+	}
+	s.pending = nil
+	return out
+}
+
+// Err returns the first error from the model, if any.
+func (s *TensorStage) Err() error {
+	return s.err
+}
+
+// Reset discards buffered samples and clears any error.
+func (s *TensorStage) Reset() {
+	s.pending = nil
+	s.err = nil
+}
+
+// run converts whole frames of samples to a tensor, runs the model on it and
+// flattens the result.
+func (s *TensorStage) run(samples []float64) []float64 {
+	if s.err != nil {
+		return nil
+	}
+	in := &Tensor{
+		Shape: []int{len(samples) / s.FrameSize, s.FrameSize},
+		Data:  make([]float32, len(samples)),
+	}
+	for i, v := range samples {
+		in.Data[i] = float32(v)
+	}
+	res, err := s.Model(in)
+	if err != nil {
+		s.err = err
+		return nil
+	}
+	out := make([]float64, len(res.Data))
+	for i, v := range res.Data {
+		out[i] = float64(v)
+	}
+	return out
+}
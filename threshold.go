@@ -0,0 +1,123 @@
+package dsp
+
+import (
+	"log"
+	"math"
+)
+
+// Histogram counts the values of the data set in the given number of equal
+// width bins spanning its minimum to its maximum. It returns the counts and
+// the bins+1 bin edges.
+func (d DataSet) Histogram(bins int) (DataSet, DataSet) {
+	counts := make([]float64, bins)
+	edges := make([]float64, bins+1)
+	if len(d) == 0 || bins <= 0 {
+		return counts, edges
+	}
+	lo, hi := d.Min(), d.Max()
+	for i := range edges {
+		edges[i] = lo + (hi-lo)*float64(i)/float64(bins)
+	}
+	for _, v := range d {
+		b := bins - 1
+		if hi > lo {
+			b = minInt(int((v-lo)/(hi-lo)*float64(bins)), bins-1)
+		}
+		counts[b]++
+	}
+	return counts, edges
+}
+
+// OtsuThreshold chooses the threshold that best splits the values of the
+// data set into two classes, by maximizing the variance between the class
+// means over a histogram with the given number of bins. It suits bimodal
+// distributions such as a signal that alternates between quiet and active.
+func (d DataSet) OtsuThreshold(bins int) float64 {
+	if bins < 1 {
+		log.Fatalf("OtsuThreshold requires at least 1 bin, got %d", bins)
+	}
+	counts, edges := d.Histogram(bins)
+	if len(d) == 0 {
+		return 0
+	}
+
+	var total, sum float64
+	for i, c := range counts {
+		total += c
+		sum += c * float64(i)
+	}
+
+	var w0, sum0, best float64
+	split := 0
+	for i := 0; i < bins-1; i++ {
+		w0 += counts[i]
+		sum0 += counts[i] * float64(i)
+		w1 := total - w0
+		if w0 == 0 || w1 == 0 {
+			continue
+		}
+		m0 := sum0 / w0
+		m1 := (sum - sum0) / w1
+		between := w0 * w1 * (m0 - m1) * (m0 - m1)
+		if between > best {
+			best = between
+			split = i
+		}
+	}
+	return edges[split+1]
+}
+
+// TriangleThreshold chooses a threshold with the triangle method: a line is
+// drawn from the histogram peak to the far end of its longer tail, and the
+// threshold is placed at the bin furthest below that line. It suits skewed
+// distributions with one dominant mode, such as background noise with rare
+// events.
+func (d DataSet) TriangleThreshold(bins int) float64 {
+	if bins < 1 {
+		log.Fatalf("TriangleThreshold requires at least 1 bin, got %d", bins)
+	}
+	counts, edges := d.Histogram(bins)
+	if len(d) == 0 {
+		return 0
+	}
+
+	peak, first, last := 0, -1, 0
+	for i, c := range counts {
+		if c > counts[peak] {
+			peak = i
+		}
+		if c > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	// walk towards the end of the longer tail
+	end, step := last, 1
+	if peak-first > last-peak {
+		end, step = first, -1
+	}
+	if end == peak {
+		return edges[peak+1]
+	}
+
+	// distance below the line from (peak, counts[peak]) to (end, counts[end])
+	dx := float64(end - peak)
+	dy := counts[end] - counts[peak]
+	norm := math.Hypot(dx, dy)
+	split, best := peak, 0.0
+	for i := peak; i != end; i += step {
+		line := counts[peak] + dy*float64(i-peak)/dx
+		dist := (line - counts[i]) * math.Abs(dx) / norm
+		if dist > best {
+			best = dist
+			split = i
+		}
+	}
+	if step > 0 {
+		return edges[split+1]
+	}
+	return edges[split]
+}
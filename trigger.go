@@ -0,0 +1,155 @@
+package dsp
+
+// TriggerMode selects the condition that fires a Trigger.
+type TriggerMode int
+
+const (
+	// EdgeTrigger fires when the signal crosses the level in the direction
+	// of the slope.
+	EdgeTrigger TriggerMode = iota
+
+	// LevelTrigger fires whenever the signal is at or above the level, or at
+	// or below it for FallingSlope, subject to the holdoff.
+	LevelTrigger
+)
+
+// TriggerSlope selects the direction of the crossings that fire an
+// EdgeTrigger.
+type TriggerSlope int
+
+const (
+	// RisingSlope fires on crossings upward through the level.
+	RisingSlope TriggerSlope = iota
+
+	// FallingSlope fires on crossings downward through the level.
+	FallingSlope
+
+	// EitherSlope fires on crossings in either direction.
+	EitherSlope
+)
+
+// Capture is a record of the signal around a trigger.
+type Capture struct {
+	// Index is the position in the stream of the sample that fired the
+	// trigger.
+	Index int
+
+	// Data holds the pre-trigger samples followed by the post-trigger
+	// samples, which start with the sample that fired the trigger.
+	Data DataSet
+}
+
+// Trigger watches a stream for trigger events like an oscilloscope and
+// records the samples around each one. It waits until the pre-trigger buffer
+// is full before it arms, and a capture is emitted once all its post-trigger
+// samples have arrived, so captures can span any number of blocks and may
+// overlap unless the holdoff prevents it.
+type Trigger struct {
+	Mode  TriggerMode
+	Slope TriggerSlope
+	Level float64
+
+	// Hysteresis is how far the signal must move back past the level to
+	// re-arm an edge trigger, which rejects noise riding on a slow edge.
+	Hysteresis float64
+
+	// PreTrigger and PostTrigger are the number of samples recorded before
+	// and from the trigger.
+	PreTrigger  int
+	PostTrigger int
+
+	// Holdoff is the number of samples after a trigger during which further
+	// triggers are ignored.
+	Holdoff int
+
+	history            *RingBuffer
+	pending            []*Capture
+	index, wait        int
+	armedUp, armedDown bool
+}
+
+// NewTrigger creates a trigger with the given number of pre-trigger and
+// post-trigger samples, no hysteresis and no holdoff.
+func NewTrigger(mode TriggerMode, slope TriggerSlope, level float64, pre, post int) *Trigger {
+	return &Trigger{
+		Mode:        mode,
+		Slope:       slope,
+		Level:       level,
+		PreTrigger:  pre,
+		PostTrigger: post,
+		history:     NewRingBuffer(pre),
+	}
+}
+
+// Write feeds a block of samples to the trigger and returns the captures
+// completed by the block, oldest first.
+func (t *Trigger) Write(X []float64) []Capture {
+	if t.history.Cap() != t.PreTrigger {
+		t.history = NewRingBuffer(t.PreTrigger)
+	}
+
+	var done []Capture
+	sample := make([]float64, 1)
+	for _, x := range X {
+		fired := t.fires(x) && t.wait == 0 && t.history.Len() == t.PreTrigger
+		if t.wait > 0 {
+			t.wait--
+		}
+
+		// extend the captures in progress, then start a new one
+		for _, c := range t.pending {
+			c.Data = append(c.Data, x)
+		}
+		if fired {
+			data := append(t.history.Values(), x)
+			t.pending = append(t.pending, &Capture{Index: t.index, Data: data})
+			t.wait = t.Holdoff
+		}
+		for len(t.pending) > 0 && len(t.pending[0].Data) >= t.PreTrigger+t.PostTrigger {
+			done = append(done, *t.pending[0])
+			t.pending = t.pending[1:]
+		}
+
+		sample[0] = x
+		t.history.Write(sample)
+		t.index++
+	}
+	return done
+}
+
+// Reset discards the captures in progress and the pre-trigger samples, and
+// restarts the sample count.
+func (t *Trigger) Reset() {
+	t.history.Reset()
+	t.pending = nil
+	t.index, t.wait = 0, 0
+	t.armedUp, t.armedDown = false, false
+}
+
+// fires updates the edge arming state with a sample and returns true if the
+// sample meets the trigger condition.
+func (t *Trigger) fires(x float64) bool {
+	if t.Mode == LevelTrigger {
+		if t.Slope == FallingSlope {
+			return x <= t.Level
+		}
+		return x >= t.Level
+	}
+
+	var fired bool
+	if t.armedUp && x >= t.Level {
+		t.armedUp = false
+		fired = t.Slope != FallingSlope
+	}
+	if t.armedDown && x <= t.Level {
+		t.armedDown = false
+		fired = fired || t.Slope != RisingSlope
+	}
+	if x < t.Level-t.Hysteresis {
+		t.armedUp = true
+	}
+	if x > t.Level+t.Hysteresis {
+		t.armedDown = true
+	}
+	return fired
+}
@@ -0,0 +1,113 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Reference values for vibration levels in dB (ISO 1683).
+const (
+	// RefAcceleration is the reference acceleration in m/s^2.
+	RefAcceleration = 1e-6
+
+	// RefVelocity is the reference velocity in m/s.
+	RefVelocity = 1e-9
+
+	// RefDisplacement is the reference displacement in m.
+	RefDisplacement = 1e-12
+)
+
+// octaveRatio is the base-ten octave frequency ratio (IEC 61260).
+var octaveRatio = math.Pow(10, 0.3)
+
+// Band is a frequency band with its lower edge, center and upper edge in Hz.
+type Band struct {
+	Lower, Center, Upper float64
+}
+
+// OctaveBands returns the fractional-octave bands (1 for octave, 3 for third
+// octave, and so on) whose centers lie between fMin and fMax, using the
+// base-ten band centers of IEC 61260 referenced to 1 kHz. The limits are
+// compared with a small tolerance so nominal centers such as 20 Hz (exactly
+// 19.95 Hz) are included.
+func OctaveBands(fraction int, fMin, fMax float64) []Band {
+	b := float64(fraction)
+	center := func(x int) float64 {
+		if fraction%2 == 1 {
+			return 1000 * math.Pow(octaveRatio, float64(x)/b)
+		}
+		return 1000 * math.Pow(octaveRatio, float64(2*x+1)/(2*b))
+	}
+
+	x := int(math.Floor(b * math.Log(fMin/1000) / math.Log(octaveRatio)))
+	var bands []Band
+	for ; ; x++ {
+		fc := center(x)
+		if fc > fMax*1.02 {
+			break
+		}
+		if fc < fMin/1.02 {
+			continue
+		}
+		edge := math.Pow(octaveRatio, 1/(2*b))
+		bands = append(bands, Band{Lower: fc / edge, Center: fc, Upper: fc * edge})
+	}
+	return bands
+}
+
+// Integrate integrates the data set over time with the trapezoidal rule, for
+// example from acceleration to velocity. The mean is removed first and, if
+// highPass is positive, the result is high-pass filtered at that frequency to
+// stop low frequency noise from accumulating into drift.
+func (d DataSet) Integrate(fS, highPass float64) DataSet {
+	mean := d.Mean()
+	out := make([]float64, len(d))
+	dt := 1 / fS
+	for i := 1; i < len(d); i++ {
+		out[i] = out[i-1] + (d[i]+d[i-1]-2*mean)*dt/2
+	}
+	if highPass > 0 {
+		hp := NewHighPassFilter(highPass, fS)
+		out = hp.Filter(hp.Filter(out))
+	}
+	return out
+}
+
+// DoubleIntegrate integrates the data set twice, for example from acceleration
+// to displacement, high-pass filtering after each stage.
+func (d DataSet) DoubleIntegrate(fS, highPass float64) DataSet {
+	return d.Integrate(fS, highPass).Integrate(fS, highPass)
+}
+
+// BandLevels returns the RMS value of the data set within each band, computed
+// by summing the power spectrum over the bins inside the band.
+func (d DataSet) BandLevels(fS float64, bands []Band) DataSet {
+	n := len(d)
+	X := realToComplex(d, NextPow2(n))
+	fft(X, false)
+	size := len(X)
+
+	levels := make([]float64, len(bands))
+	for i, band := range bands {
+		var power float64
+		for k := 1; k <= size/2; k++ {
+			f := float64(k) * fS / float64(size)
+			if f < band.Lower || f >= band.Upper {
+				continue
+			}
+			a := cmplx.Abs(X[k])
+			p := 2 * a * a
+			if k == size/2 {
+				p /= 2
+			}
+			power += p
+		}
+		levels[i] = math.Sqrt(power / float64(size) / float64(n))
+	}
+	return levels
+}
+
+// Level converts an RMS value to decibels relative to the reference value.
+func Level(rms, ref float64) float64 {
+	return 20 * math.Log10(rms/ref)
+}
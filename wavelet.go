@@ -0,0 +1,118 @@
+package dsp
+
+import "math"
+
+// Wavelet is an orthogonal wavelet defined by its scaling (low-pass) filter.
+type Wavelet struct {
+	Name    string
+	Scaling DataSet
+}
+
+// Haar is the Haar wavelet, the shortest orthogonal wavelet.
+var Haar = Wavelet{
+	Name:    "haar",
+	Scaling: DataSet{math.Sqrt2 / 2, math.Sqrt2 / 2},
+}
+
+// Daubechies2 is the 4 tap Daubechies wavelet with two vanishing moments.
+var Daubechies2 = Wavelet{
+	Name: "db2",
+	Scaling: DataSet{
+		0.48296291314453416, 0.8365163037378079,
+		0.22414386804201339, -0.12940952255126037,
+	},
+}
+
+// Daubechies4 is the 8 tap Daubechies wavelet with four vanishing moments.
+var Daubechies4 = Wavelet{
+	Name: "db4",
+	Scaling: DataSet{
+		0.23037781330885523, 0.7148465705525415,
+		0.6308807679295904, -0.02798376941698385,
+		-0.18703481171888114, 0.030841381835986965,
+		0.032883011666982945, -0.010597401784997278,
+	},
+}
+
+// Detail returns the wavelet (high-pass) filter, the alternating flip of the
+// scaling filter.
+func (w Wavelet) Detail() DataSet {
+	L := len(w.Scaling)
+	g := make([]float64, L)
+	for k := range g {
+		g[k] = w.Scaling[L-1-k]
+		if k%2 == 1 {
+			g[k] = -g[k]
+		}
+	}
+	return g
+}
+
+// WaveletCoefficients holds a multi-level discrete wavelet transform.
+type WaveletCoefficients struct {
+	Wavelet Wavelet
+
+	// Approximation is the coarsest low-pass band.
+	Approximation DataSet
+
+	// Details holds the high-pass band of each level, finest first.
+	Details []DataSet
+
+	// lengths holds the signal length at the input of each level.
+	lengths []int
+}
+
+// Levels returns the number of decomposition levels.
+func (c *WaveletCoefficients) Levels() int {
+	return len(c.Details)
+}
+
+// DWT computes the discrete wavelet transform of the data set over the given
+// number of levels. Each level splits the approximation from the previous
+// level into half length low-pass and high-pass bands. The signal is treated
+// as periodic, so the transform is orthogonal and preserves energy; an odd
+// length band is extended by repeating its last sample.
+func (d DataSet) DWT(w Wavelet, levels int) *WaveletCoefficients {
+	g := w.Detail()
+	c := &WaveletCoefficients{Wavelet: w}
+	a := append(DataSet(nil), d...)
+	for l := 0; l < levels && len(a) > 1; l++ {
+		c.lengths = append(c.lengths, len(a))
+		if len(a)%2 == 1 {
+			a = append(a, a[len(a)-1])
+		}
+		N := len(a)
+		approx := make([]float64, N/2)
+		detail := make([]float64, N/2)
+		for n := range approx {
+			for k := range w.Scaling {
+				v := a[(2*n+k)%N]
+				approx[n] += w.Scaling[k] * v
+				detail[n] += g[k] * v
+			}
+		}
+		c.Details = append(c.Details, detail)
+		a = approx
+	}
+	c.Approximation = a
+	return c
+}
+
+// Inverse reconstructs the signal from the wavelet coefficients.
+func (c *WaveletCoefficients) Inverse() DataSet {
+	h := c.Wavelet.Scaling
+	g := c.Wavelet.Detail()
+	a := c.Approximation
+	for l := len(c.Details) - 1; l >= 0; l-- {
+		detail := c.Details[l]
+		N := 2 * len(a)
+		x := make([]float64, N)
+		for n := range a {
+			for k := range h {
+				x[(2*n+k)%N] += h[k]*a[n] + g[k]*detail[n]
+			}
+		}
+		a = x[:c.lengths[l]]
+	}
+	return a
+}
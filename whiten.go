@@ -0,0 +1,24 @@
+package dsp
+
+// Whiten flattens the spectrum of the data set by dividing each frequency bin
+// by the magnitude spectrum smoothed over smoothBins bins. Whitening before
+// correlation or detection keeps strong colored noise from masking weak
+// broadband signals.
+func (d DataSet) Whiten(smoothBins int) DataSet {
+	n := NextPow2(len(d))
+	X := realToComplex(d, n)
+	fft(X, false)
+
+	mag := Magnitude(X).MovingAverage(smoothBins)
+	floor := 1e-12 * mag.Max()
+	for k := range X {
+		X[k] /= complex(mag[k]+floor, 0)
+	}
+	fft(X, true)
+
+	values := make([]float64, len(d))
+	for i := range values {
+		values[i] = real(X[i])
+	}
+	return values
+}
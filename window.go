@@ -0,0 +1,124 @@
+package dsp
+
+import "math"
+
+// WindowFunc generates a window of length n.
+type WindowFunc func(n int) DataSet
+
+// The window generators return periodic windows, which tile exactly under
+// overlap-add and have the right shape for spectral analysis. Use Symmetric
+// for filter design, where the window must be symmetric about its center.
+
+// Rectangular returns a window of n ones.
+func Rectangular(n int) DataSet {
+	return cosineSum(n, 1)
+}
+
+// Hann returns a periodic Hann window of length n.
+func Hann(n int) DataSet {
+	return cosineSum(n, 0.5, 0.5)
+}
+
+// Hamming returns a periodic Hamming window of length n.
+func Hamming(n int) DataSet {
+	return cosineSum(n, 0.54, 0.46)
+}
+
+// Blackman returns a periodic Blackman window of length n.
+func Blackman(n int) DataSet {
+	return cosineSum(n, 0.42, 0.5, 0.08)
+}
+
+// BlackmanHarris returns a periodic four term Blackman-Harris window of length
+// n, with sidelobes 92 dB down.
+func BlackmanHarris(n int) DataSet {
+	return cosineSum(n, 0.35875, 0.48829, 0.14128, 0.01168)
+}
+
+// FlatTop returns a periodic flat-top window of length n. Its wide, flat main
+// lobe reads the amplitude of a sine accurately wherever it falls between
+// bins.
+func FlatTop(n int) DataSet {
+	return cosineSum(n, 0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368)
+}
+
+// Kaiser returns a periodic Kaiser window of length n. Beta trades main lobe
+// width for sidelobe level: 0 is rectangular, about 5 is similar to Hamming
+// and about 8.6 is similar to Blackman.
+func Kaiser(n int, beta float64) DataSet {
+	w := make([]float64, n)
+	norm := besselI0(beta)
+	for i := range w {
+		x := 2*float64(i)/float64(n) - 1
+		w[i] = besselI0(beta*math.Sqrt(math.Max(0, 1-x*x))) / norm
+	}
+	return w
+}
+
+// Tukey returns a periodic Tukey window of length n, which is flat over all
+// but a fraction alpha of its length and tapered with a cosine at each end.
+// An alpha of 0 is rectangular and 1 is Hann.
+func Tukey(n int, alpha float64) DataSet {
+	w := make([]float64, n)
+	for i := range w {
+		x := float64(i) / float64(n)
+		switch {
+		case alpha <= 0:
+			w[i] = 1
+		case x < alpha/2:
+			w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*x/alpha)
+		case x > 1-alpha/2:
+			w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*(1-x)/alpha)
+		default:
+			w[i] = 1
+		}
+	}
+	return w
+}
+
+// Symmetric returns the symmetric form of a window of length n, whose first
+// and last samples are equal.
+func Symmetric(fn WindowFunc, n int) DataSet {
+	if n <= 1 {
+		return Rectangular(n)
+	}
+	w := fn(n - 1)
+	return append(w, w[0])
+}
+
+// ApplyWindow returns the data set multiplied sample by sample by the window.
+func (d DataSet) ApplyWindow(w DataSet) DataSet {
+	checkLengths("ApplyWindow", d, w)
+	values := make([]float64, len(d))
+	for i, v := range d {
+		values[i] = v * w[i]
+	}
+	return values
+}
+
+// cosineSum returns the periodic window sum_k (-1)^k a_k cos(2 pi k i / n).
+func cosineSum(n int, a ...float64) DataSet {
+	w := make([]float64, n)
+	for i := range w {
+		sign := 1.0
+		for k, c := range a {
+			w[i] += sign * c * math.Cos(2*math.Pi*float64(k*i)/float64(n))
+			sign = -sign
+		}
+	}
+	return w
+}
+
+// besselI0 returns the zeroth order modified Bessel function of the first kind.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	half := x / 2
+	for k := 1; k < 500; k++ {
+		term *= (half / float64(k)) * (half / float64(k))
+		sum += term
+		if term < sum*1e-17 {
+			break
+		}
+	}
+	return sum
+}
@@ -0,0 +1,101 @@
+package dsp
+
+import (
+	"log"
+	"math"
+)
+
+// FrameFunc is called by WOLA for each analysis frame with the frame index and
+// its one-sided spectrum, which it may modify in place.
+type FrameFunc func(frame int, bins []complex128)
+
+// WOLA is a weighted overlap-add analysis/synthesis engine. Each frame of Size
+// samples, Hop samples apart, is multiplied by the analysis window and
+// transformed; after the frame callback the spectrum is transformed back,
+// multiplied by the synthesis window and overlap-added. When the windows
+// satisfy the perfect reconstruction condition an unmodified spectrum
+// reproduces the input exactly.
+type WOLA struct {
+	Size, Hop           int
+	Analysis, Synthesis DataSet
+}
+
+// NewWOLA creates a WOLA engine. If the windows are nil a square-root periodic
+// Hann window is used for both, which reconstructs perfectly for hops of
+// Size/2, Size/4 and so on.
+func NewWOLA(size, hop int, analysis, synthesis DataSet) *WOLA {
+	if size <= 0 || hop <= 0 {
+		log.Fatalf("NewWOLA requires a positive size and hop, got %d and %d", size, hop)
+	}
+	if analysis == nil {
+		analysis = sqrtHann(size)
+	}
+	if synthesis == nil {
+		synthesis = sqrtHann(size)
+	}
+	return &WOLA{Size: size, Hop: hop, Analysis: analysis, Synthesis: synthesis}
+}
+
+// Gain returns the overlap-added product of the analysis and synthesis windows
+// at each of the Hop positions within a frame. Reconstruction is perfect when
+// all the values are equal.
+func (w *WOLA) Gain() DataSet {
+	gain := make([]float64, w.Hop)
+	for i := 0; i < w.Size; i++ {
+		gain[i%w.Hop] += w.Analysis[i] * w.Synthesis[i]
+	}
+	return gain
+}
+
+// CheckPR returns whether the windows and hop give perfect reconstruction
+// within the tolerance, along with the relative ripple of the overlap-added
+// window gain.
+func (w *WOLA) CheckPR(tolerance float64) (bool, float64) {
+	gain := w.Gain()
+	min, max := gain.Bounds()
+	mean := gain.Mean()
+	if mean == 0 {
+		return false, math.Inf(1)
+	}
+	ripple := (max - min) / mean
+	return ripple <= tolerance, ripple
+}
+
+// Process runs the analysis, callback and synthesis over the data set and
+// returns an output of the same length. A nil callback passes the spectra
+// through unchanged.
+func (w *WOLA) Process(x DataSet, fn FrameFunc) DataSet {
+	if w.Size <= 0 || w.Hop <= 0 {
+		log.Fatalf("WOLA requires a positive Size and Hop, got %d and %d", w.Size, w.Hop)
+	}
+	pad := w.Size
+	padded := make([]float64, len(x)+2*pad)
+	copy(padded[pad:], x)
+	out := make([]float64, len(padded))
+	norm := w.Gain().Mean()
+
+	frame := make([]complex128, w.Size)
+	for f, start := 0, 0; start+w.Size <= len(padded); f, start = f+1, start+w.Hop {
+		for i := 0; i < w.Size; i++ {
+			frame[i] = complex(padded[start+i]*w.Analysis[i], 0)
+		}
+		bins := FFT(frame)[:w.Size/2+1]
+		if fn != nil {
+			fn(f, bins)
+		}
+		y := irfft(bins, w.Size)
+		for i := 0; i < w.Size; i++ {
+			out[start+i] += y[i] * w.Synthesis[i] / norm
+		}
+	}
+	return out[pad : pad+len(x)]
+}
+
+// sqrtHann returns the square root of a periodic Hann window of length n.
+func sqrtHann(n int) DataSet {
+	w := Hann(n)
+	for i := range w {
+		w[i] = math.Sqrt(w[i])
+	}
+	return w
+}
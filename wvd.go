@@ -0,0 +1,91 @@
+package dsp
+
+import "math/cmplx"
+
+// WignerVille computes the Wigner-Ville distribution of the analytic signal
+// of the data set at every sample. It has ideal resolution for a single
+// linear chirp, whose energy lies exactly on its instantaneous frequency, but
+// produces oscillating cross terms midway between any two components. The
+// cost grows with the square of the length, so keep data sets short.
+func (d DataSet) WignerVille(fS float64) *TimeFrequency {
+	L := len(d) / 2
+	return wignerVille(d.AnalyticSignal(), nil, Rectangular(2*L+1), fS)
+}
+
+// PseudoWignerVille computes the pseudo Wigner-Ville distribution, which
+// tapers the lag product with lagWindow, an odd length window. This smooths
+// along frequency, suppressing the cross terms between components at
+// different times at the cost of frequency resolution.
+func (d DataSet) PseudoWignerVille(lagWindow DataSet, fS float64) *TimeFrequency {
+	return wignerVille(d.AnalyticSignal(), nil, lagWindow, fS)
+}
+
+// SmoothedPseudoWignerVille computes the smoothed pseudo Wigner-Ville
+// distribution, which additionally averages the lag product over time with
+// timeWindow, an odd length window. This suppresses the cross terms between
+// components at different frequencies, with time and frequency smoothing
+// controlled independently.
+func (d DataSet) SmoothedPseudoWignerVille(timeWindow, lagWindow DataSet, fS float64) *TimeFrequency {
+	return wignerVille(d.AnalyticSignal(), timeWindow, lagWindow, fS)
+}
+
+// wignerVille computes the distribution of z with optional time smoothing
+// window g and lag window h, both of odd length and centered.
+func wignerVille(z IQ, g, h DataSet, fS float64) *TimeFrequency {
+	L := len(h) / 2
+	nfft := NextPow2(2*L + 1)
+	hc := h[L]
+
+	var gSum float64
+	P := 0
+	if g != nil {
+		P = len(g) / 2
+		gSum = g.Sum()
+	}
+
+	at := func(i int) complex128 {
+		if i < 0 || i >= len(z) {
+			return 0
+		}
+		return z[i]
+	}
+
+	plan := NewFFTPlan(nfft)
+	kernel := make([]complex128, nfft)
+	tf := &TimeFrequency{
+		Data:  make([][]float64, len(z)),
+		Times: make(DataSet, len(z)),
+		Freqs: make(DataSet, nfft),
+	}
+	for k := range tf.Freqs {
+		// the lag product oscillates at twice the signal frequency
+		tf.Freqs[k] = float64(k) * fS / float64(2*nfft)
+	}
+
+	for n := range z {
+		for i := range kernel {
+			kernel[i] = 0
+		}
+		for m := -L; m <= L; m++ {
+			var r complex128
+			if g == nil {
+				r = at(n+m) * cmplx.Conj(at(n-m))
+			} else {
+				for p := -P; p <= P; p++ {
+					r += complex(g[p+P], 0) * at(n+p+m) * cmplx.Conj(at(n+p-m))
+				}
+				r /= complex(gSum, 0)
+			}
+			kernel[(m+nfft)%nfft] += r * complex(h[m+L]/hc, 0)
+		}
+		plan.Forward(kernel, kernel)
+
+		row := make([]float64, nfft)
+		for k, v := range kernel {
+			row[k] = real(v)
+		}
+		tf.Data[n] = row
+		tf.Times[n] = float64(n) / fS
+	}
+	return tf
+}
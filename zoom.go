@@ -0,0 +1,49 @@
+package dsp
+
+import "math"
+
+// ZoomFFT computes a high resolution spectrum over a narrow band centered on
+// fC with the given span. The signal is mixed down to baseband, low-pass
+// filtered, decimated and transformed with an n point FFT (rounded up to a
+// power of two). It returns the bin frequencies in ascending order and the
+// corresponding complex bins.
+func (d DataSet) ZoomFFT(fC, span, fS float64, n int) (DataSet, []complex128) {
+	n = NextPow2(n)
+
+	// leave a transition band above the low-pass cutoff before aliasing
+	factor := int(fS / (1.25 * span))
+	if factor < 1 {
+		factor = 1
+	}
+
+	I := make([]float64, len(d))
+	Q := make([]float64, len(d))
+	for i := 0; i < len(d); i++ {
+		s, c := math.Sincos(2 * math.Pi * fC * float64(i) / fS)
+		I[i] = d[i] * c
+		Q[i] = -d[i] * s
+	}
+
+	if factor > 1 {
+		lp := NewLowPassFilter(span/2, fS)
+		I = lp.Filter(lp.Filter(I))
+		Q = lp.Filter(lp.Filter(Q))
+	}
+
+	X := make([]complex128, n)
+	for i := 0; i < n && i*factor < len(d); i++ {
+		X[i] = complex(I[i*factor], Q[i*factor])
+	}
+	fft(X, false)
+
+	// reorder so the bins run from the lowest to the highest frequency
+	rate := fS / float64(factor)
+	freqs := make([]float64, n)
+	bins := make([]complex128, n)
+	for i := 0; i < n; i++ {
+		k := (i + n/2) % n
+		freqs[i] = fC + float64(i-n/2)*rate/float64(n)
+		bins[i] = X[k]
+	}
+	return freqs, bins
+}